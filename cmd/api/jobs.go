@@ -0,0 +1,38 @@
+package main
+
+import (
+	"database/sql"
+
+	"github.com/edutav/licentia-usoris/infrastructure/auth"
+	"github.com/edutav/licentia-usoris/infrastructure/scheduler"
+	"github.com/edutav/licentia-usoris/internal/config"
+	"github.com/edutav/licentia-usoris/internal/domain/reporitory/postgres"
+	"github.com/edutav/licentia-usoris/internal/usecases"
+)
+
+// newScheduler creates the scheduler of the maintenance jobs
+func newScheduler(db *sql.DB, blacklist *auth.Blacklist, cfg config.MaintenanceConfig) *scheduler.Scheduler {
+	maintenanceUseCase := usecases.NewMaintenanceUseCase(
+		postgres.NewUserRepository(db),
+		postgres.NewVerificationCodeRepository(db),
+	)
+
+	s := scheduler.NewScheduler(db)
+	s.Register(scheduler.Job{
+		Name:     "purge_blacklist",
+		Interval: cfg.BlacklistPurgeInterval,
+		Run:      blacklist.Remove,
+	})
+	s.Register(scheduler.Job{
+		Name:     "purge_pre_registrations",
+		Interval: cfg.PreRegistrationPurgeInterval,
+		Run:      maintenanceUseCase.PurgeExpiredPreRegistrations,
+	})
+	s.Register(scheduler.Job{
+		Name:     "purge_verification_codes",
+		Interval: cfg.VerificationCodePurgeInterval,
+		Run:      maintenanceUseCase.PurgeExpiredVerificationCodes,
+	})
+
+	return s
+}
@@ -1,15 +1,26 @@
 package main
 
 import (
-	"log"
+	"context"
+	"database/sql"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	_ "github.com/edutav/licentia-usoris/docs"
+	"github.com/edutav/licentia-usoris/infrastructure/auth"
 	"github.com/edutav/licentia-usoris/infrastructure/database"
 	"github.com/edutav/licentia-usoris/infrastructure/email"
+	"github.com/edutav/licentia-usoris/infrastructure/health"
+	"github.com/edutav/licentia-usoris/infrastructure/logger"
+	"github.com/edutav/licentia-usoris/infrastructure/metrics"
+	"github.com/edutav/licentia-usoris/infrastructure/scheduler"
 	"github.com/edutav/licentia-usoris/infrastructure/server"
+	"github.com/edutav/licentia-usoris/infrastructure/tracing"
 	"github.com/edutav/licentia-usoris/internal/config"
+	"github.com/sirupsen/logrus"
 )
 
 // @title Licentia Usoris API
@@ -23,32 +34,167 @@ import (
 // @contact.email support@swagger.io
 // @license.name Apache 2.0
 // @license.url http://www.apache.org/licenses/LICENSE-2.0.html
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Type "Bearer" followed by a space and the access token.
 func main() {
 	// Define timezone default to UTC for the application
 	time.Local = time.UTC
 
-	log.Println("Starting Aplication")
-
 	cfg, err := config.Load()
 	if err != nil {
-		log.Printf("Error loading config: %s", err)
+		logger.Base().WithError(err).Fatal("Error loading config")
+	}
+
+	// Log at the configured level and format from here on
+	if err := logger.Setup(cfg.Log); err != nil {
+		logger.Base().WithError(err).Fatal("Error initializing logger")
+	}
+
+	logger.Base().WithFields(logrus.Fields{
+		"env":    cfg.Env.Env,
+		"config": cfg.Env.File,
+	}).Info("Starting application")
+
+	// Trace the requests, before the database connection is traced too
+	flushTraces, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		logger.Base().WithError(err).Fatal("Error initializing tracing")
 	}
 
 	db, err := database.NewConnectionPostgres(
 		cfg.Database.Host, cfg.Database.Port, cfg.Database.User, cfg.Database.Password, cfg.Database.Name,
 	)
 	if err != nil {
-		log.Fatalf("Error connecting to database: %s", err)
+		logger.Base().WithError(err).Fatal("Error connecting to database")
+	}
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		logger.Base().WithError(err).Fatal("Error loading migrations")
+	}
+
+	// Apply the pending migrations before serving
+	if cfg.Database.AutoMigrate {
+		if _, err := migrator.Up(context.Background()); err != nil {
+			logger.Base().WithError(err).Fatal("Error applying migrations")
+		}
 	}
-	defer db.Close()
 
 	// Initialize email sender
 	emailSender := email.NewEmailSender(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Username, cfg.SMTP.Password)
 
-	server := server.NewServer(db, emailSender, cfg)
+	// Initialize token service
+	tokenService, err := auth.NewJWTService(
+		cfg.JWT.SecretKey, cfg.JWT.Issuer, cfg.JWT.Audience, cfg.JWT.AccessTokenTTL,
+	)
+	if err != nil {
+		logger.Base().WithError(err).Fatal("Error initializing token service")
+	}
+
+	// Initialize token blacklist
+	blacklist := auth.NewBlacklist(db)
+
+	if cfg.Verification.Secret == "" {
+		logger.Base().Fatal("Error initializing verification codes: verification.secret not configured")
+	}
+
+	// Initialize the cipher of the secrets stored in the database
+	secretCipher, err := auth.NewSecretCipher(cfg.MFA.EncryptionKey)
+	if err != nil {
+		logger.Base().WithError(err).Fatal("Error initializing secret cipher")
+	}
+
+	// Expose the stats of the connection pool with the metrics
+	metrics.RegisterDBStats(db, cfg.Database.Name)
+
+	// Checks of the dependencies the readiness probe reports
+	checks := []health.Check{health.PostgresCheck(db), health.MigrationsCheck(migrator)}
+	if cfg.Health.CheckSMTP {
+		checks = append(checks, health.SMTPCheck(emailSender))
+	}
+	readiness := health.NewReadiness(cfg.Health.CheckTimeout, checks...)
+
+	handler := server.NewServer(db, emailSender, tokenService, blacklist, secretCipher, readiness, cfg)
+	httpServer := server.NewHTTPServer(handler, cfg.Server)
+
+	// Stop on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Run the maintenance jobs in the background, they are stopped after the requests are drained
+	jobs := newScheduler(db, blacklist, cfg.Maintenance)
+	jobs.Start(context.Background())
+
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Base().WithField("port", cfg.Server.Port).Info("Server is running")
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			serverErr <- err
+		}
+	}()
+
+	var serveErr error
+	select {
+	case serveErr = <-serverErr:
+		logger.Base().WithError(serveErr).Error("Error starting server")
+	case <-ctx.Done():
+		logger.Base().Info("Shutting down")
+	}
+
+	// A second signal kills the process
+	stop()
+
+	// Stay up but unready for a while, so traffic stops being routed here before the listener closes
+	readiness.SetShuttingDown()
+	if serveErr == nil && cfg.Server.ShutdownDelay > 0 {
+		logger.Base().WithField("delay", cfg.Server.ShutdownDelay.String()).Info("Waiting for traffic to drain")
+		time.Sleep(cfg.Server.ShutdownDelay)
+	}
+
+	shutdown(httpServer, jobs, emailSender, db, flushTraces, cfg.Server.ShutdownTimeout)
+
+	if serveErr != nil {
+		os.Exit(1)
+	}
+}
+
+// shutdown drains the in-flight requests, then stops the jobs, the email sender, the
+// database pool and the tracing in that order, all within the grace period
+func shutdown(
+	httpServer *http.Server,
+	jobs *scheduler.Scheduler,
+	emailSender *email.Sender,
+	db *sql.DB,
+	flushTraces func(context.Context) error,
+	gracePeriod time.Duration,
+) {
+	if gracePeriod <= 0 {
+		gracePeriod = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), gracePeriod)
+	defer cancel()
+
+	if err := httpServer.Shutdown(ctx); err != nil {
+		logger.Base().WithError(err).Error("Error draining connections")
+	}
+
+	if err := jobs.Stop(ctx); err != nil {
+		logger.Base().WithError(err).Error("Error stopping jobs")
+	}
+
+	if err := emailSender.Close(ctx); err != nil {
+		logger.Base().WithError(err).Error("Error closing email sender")
+	}
 
-	log.Println("Server is running on port", cfg.Server.Port)
-	if err := http.ListenAndServe(":"+cfg.Server.Port, server); err != nil {
-		log.Fatalf("Error starting server: %s", err)
+	if err := db.Close(); err != nil {
+		logger.Base().WithError(err).Error("Error closing database")
 	}
+
+	if err := flushTraces(ctx); err != nil {
+		logger.Base().WithError(err).Error("Error flushing traces")
+	}
+
+	logger.Base().Info("Server stopped")
 }
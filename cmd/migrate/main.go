@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/edutav/licentia-usoris/infrastructure/database"
+	"github.com/edutav/licentia-usoris/infrastructure/logger"
+	"github.com/edutav/licentia-usoris/internal/config"
+)
+
+const usage = `Usage: migrate [flags] <command>
+
+Commands:
+  up        apply every pending migration
+  down      revert the last applied migrations (see -steps)
+  status    list the migrations and when they were applied
+  baseline  record the migrations up to -version as applied without running them,
+            to adopt a database created before the migrations
+
+Flags:
+`
+
+func main() {
+	steps := flag.Int("steps", 1, "number of migrations to revert with down")
+	version := flag.Int64("version", 0, "last migration recorded by baseline, 0 for every migration")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Base().WithError(err).Fatal("Failed to load config")
+	}
+	if err := logger.Setup(cfg.Log); err != nil {
+		logger.Base().WithError(err).Fatal("Failed to initialize logger")
+	}
+
+	db, err := database.NewConnectionPostgres(
+		cfg.Database.Host, cfg.Database.Port, cfg.Database.User, cfg.Database.Password, cfg.Database.Name,
+	)
+	if err != nil {
+		logger.Base().WithError(err).Fatal("Failed to connect to database")
+	}
+	defer db.Close()
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		logger.Base().WithError(err).Fatal("Failed to load migrations")
+	}
+
+	ctx := context.Background()
+
+	switch flag.Arg(0) {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			logger.Base().WithError(err).Fatal("Failed to apply migrations")
+		}
+		logger.Base().WithField("count", len(applied)).Info("Migrations applied")
+	case "down":
+		if *steps < 1 {
+			logger.Base().WithField("steps", *steps).Fatal("steps must be at least 1")
+		}
+		reverted, err := migrator.Down(ctx, *steps)
+		if err != nil {
+			logger.Base().WithError(err).Fatal("Failed to revert migrations")
+		}
+		logger.Base().WithField("count", len(reverted)).Info("Migrations reverted")
+	case "baseline":
+		if *version < 0 {
+			logger.Base().WithField("version", *version).Fatal("version must not be negative")
+		}
+		recorded, err := migrator.Baseline(ctx, *version)
+		if err != nil {
+			logger.Base().WithError(err).Fatal("Failed to record migrations")
+		}
+		logger.Base().WithField("count", len(recorded)).Info("Migrations recorded as applied")
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			logger.Base().WithError(err).Fatal("Failed to get migration status")
+		}
+		for _, s := range status {
+			appliedAt := "pending"
+			if s.Applied() {
+				appliedAt = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d_%-40s %s\n", s.Version, s.Name, appliedAt)
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/edutav/licentia-usoris/internal/usecases/validator"
+	"github.com/edutav/licentia-usoris/internal/utils/helpers"
+	"gopkg.in/yaml.v3"
+)
+
+// SeedFile describes the roles, permissions and users to seed
+type SeedFile struct {
+	Permissions []SeedPermission `yaml:"permissions" json:"permissions"`
+	Roles       []SeedRole       `yaml:"roles" json:"roles"`
+	Users       []SeedUser       `yaml:"users" json:"users"`
+}
+
+type SeedPermission struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description"`
+}
+
+type SeedRole struct {
+	Name        string   `yaml:"name" json:"name"`
+	Description string   `yaml:"description" json:"description"`
+	Permissions []string `yaml:"permissions" json:"permissions"`
+}
+
+type SeedUser struct {
+	Name        string   `yaml:"name" json:"name"`
+	Email       string   `yaml:"email" json:"email"`
+	PasswordEnv string   `yaml:"password_env" json:"password_env"`
+	Roles       []string `yaml:"roles" json:"roles"`
+}
+
+// loadSeedFile reads a YAML or JSON seed file and validates it
+func loadSeedFile(path string) (*SeedFile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON is valid YAML, so a single decoder reads both formats
+	seed := &SeedFile{}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(seed); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", path, err)
+	}
+
+	if err := seed.validate(); err != nil {
+		return nil, fmt.Errorf("validating %s: %w", path, err)
+	}
+
+	return seed, nil
+}
+
+// validate checks the names and references of the seed file
+func (s *SeedFile) validate() error {
+	permissions := map[string]bool{}
+	for _, permission := range s.Permissions {
+		if err := validator.ValidatePermissionName(permission.Name); err != nil {
+			return fmt.Errorf("permission %q: %w", permission.Name, err)
+		}
+		if permissions[permission.Name] {
+			return fmt.Errorf("permission %q declared twice", permission.Name)
+		}
+		permissions[permission.Name] = true
+	}
+
+	roles := map[string]bool{}
+	for _, role := range s.Roles {
+		if err := validator.ValidateRoleName(role.Name); err != nil {
+			return fmt.Errorf("role %q: %w", role.Name, err)
+		}
+		if roles[role.Name] {
+			return fmt.Errorf("role %q declared twice", role.Name)
+		}
+		roles[role.Name] = true
+	}
+
+	emails := map[string]bool{}
+	for _, user := range s.Users {
+		if err := helpers.ValidateEmail(user.Email); err != nil {
+			return fmt.Errorf("user %q: %w", user.Email, err)
+		}
+		if err := validator.ValidateUserName(user.Name); err != nil {
+			return fmt.Errorf("user %q: %w", user.Email, err)
+		}
+		if user.PasswordEnv == "" {
+			return fmt.Errorf("user %q: password_env is required", user.Email)
+		}
+		if emails[user.Email] {
+			return fmt.Errorf("user %q declared twice", user.Email)
+		}
+		emails[user.Email] = true
+	}
+
+	return nil
+}
+
+// resolvePassword reads the password of the user from its environment variable,
+// or from the file named by the variable with the _FILE suffix
+func resolvePassword(user SeedUser) (string, error) {
+	if password := os.Getenv(user.PasswordEnv); password != "" {
+		return password, nil
+	}
+
+	if file := os.Getenv(user.PasswordEnv + "_FILE"); file != "" {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("reading %s_FILE: %w", user.PasswordEnv, err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	}
+
+	return "", fmt.Errorf("set %s or %s_FILE with the password of %s", user.PasswordEnv, user.PasswordEnv, user.Email)
+}
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/edutav/licentia-usoris/internal/usecases/validator"
+	"github.com/lib/pq"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// change is a single statement of the seed plan
+type change struct {
+	summary string
+	apply   func(ctx context.Context, tx *sql.Tx) error
+}
+
+// state is what the database already holds of the seed file
+type state struct {
+	permissions map[string]string
+	roles       map[string]string
+	grants      map[[2]string]bool
+	users       map[string]bool
+	userRoles   map[[2]string]bool
+}
+
+// loadState reads the permissions, roles, grants and seeded users of the database
+func loadState(ctx context.Context, db *sql.DB, seed *SeedFile) (*state, error) {
+	s := &state{
+		permissions: map[string]string{},
+		roles:       map[string]string{},
+		grants:      map[[2]string]bool{},
+		users:       map[string]bool{},
+		userRoles:   map[[2]string]bool{},
+	}
+
+	emails := make([]string, 0, len(seed.Users))
+	for _, user := range seed.Users {
+		emails = append(emails, user.Email)
+	}
+
+	queries := []struct {
+		query string
+		args  []any
+		scan  func(a, b string)
+	}{
+		{
+			query: `SELECT name, COALESCE(description, '') FROM permissions`,
+			scan:  func(a, b string) { s.permissions[a] = b },
+		},
+		{
+			query: `SELECT name, COALESCE(description, '') FROM roles`,
+			scan:  func(a, b string) { s.roles[a] = b },
+		},
+		{
+			query: `
+				SELECT r.name, p.name
+				FROM role_permissions rp
+				INNER JOIN roles r ON r.uuid = rp.role_id
+				INNER JOIN permissions p ON p.uuid = rp.permission_id`,
+			scan: func(a, b string) { s.grants[[2]string{a, b}] = true },
+		},
+		{
+			query: `SELECT email, '' FROM users WHERE email = ANY($1)`,
+			args:  []any{pq.Array(emails)},
+			scan:  func(a, _ string) { s.users[a] = true },
+		},
+		{
+			query: `
+				SELECT u.email, r.name
+				FROM user_roles ur
+				INNER JOIN users u ON u.uuid = ur.user_id
+				INNER JOIN roles r ON r.uuid = ur.role_id
+				WHERE u.email = ANY($1)`,
+			args: []any{pq.Array(emails)},
+			scan: func(a, b string) { s.userRoles[[2]string{a, b}] = true },
+		},
+	}
+
+	for _, q := range queries {
+		rows, err := db.QueryContext(ctx, q.query, q.args...)
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			var a, b string
+			if err := rows.Scan(&a, &b); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			q.scan(a, b)
+		}
+
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// buildPlan lists the changes needed for the database to hold the seed file.
+// Nothing is ever removed: roles, grants and users created through the API are kept.
+func buildPlan(seed *SeedFile, current *state) ([]change, error) {
+	var plan []change
+
+	// Permissions known after the plan, used to expand the role grants
+	known := map[string]bool{}
+	for name := range current.permissions {
+		known[name] = true
+	}
+
+	for _, permission := range seed.Permissions {
+		permission := permission
+		known[permission.Name] = true
+
+		description, exists := current.permissions[permission.Name]
+		switch {
+		case !exists:
+			plan = append(plan, change{
+				summary: fmt.Sprintf("+ permission %s", permission.Name),
+				apply:   upsert("permissions", permission.Name, permission.Description),
+			})
+		case description != permission.Description:
+			plan = append(plan, change{
+				summary: fmt.Sprintf("~ permission %s: description %q -> %q", permission.Name, description, permission.Description),
+				apply:   upsert("permissions", permission.Name, permission.Description),
+			})
+		}
+	}
+
+	roles := map[string]bool{}
+	for name := range current.roles {
+		roles[name] = true
+	}
+
+	for _, role := range seed.Roles {
+		role := role
+		roles[role.Name] = true
+
+		description, exists := current.roles[role.Name]
+		switch {
+		case !exists:
+			plan = append(plan, change{
+				summary: fmt.Sprintf("+ role %s", role.Name),
+				apply:   upsert("roles", role.Name, role.Description),
+			})
+		case description != role.Description:
+			plan = append(plan, change{
+				summary: fmt.Sprintf("~ role %s: description %q -> %q", role.Name, description, role.Description),
+				apply:   upsert("roles", role.Name, role.Description),
+			})
+		}
+
+		permissions, err := expandPermissions(role.Permissions, known)
+		if err != nil {
+			return nil, fmt.Errorf("role %q: %w", role.Name, err)
+		}
+
+		for _, permission := range permissions {
+			if current.grants[[2]string{role.Name, permission}] {
+				continue
+			}
+
+			plan = append(plan, change{
+				summary: fmt.Sprintf("+ grant %s -> %s", role.Name, permission),
+				apply:   grant(role.Name, permission),
+			})
+		}
+	}
+
+	for _, user := range seed.Users {
+		user := user
+
+		if !current.users[user.Email] {
+			plan = append(plan, change{
+				summary: fmt.Sprintf("+ user %s (password from %s)", user.Email, user.PasswordEnv),
+				apply:   createUser(user),
+			})
+		}
+
+		for _, role := range user.Roles {
+			if !roles[role] {
+				return nil, fmt.Errorf("user %q: unknown role %q", user.Email, role)
+			}
+
+			if current.userRoles[[2]string{user.Email, role}] {
+				continue
+			}
+
+			plan = append(plan, change{
+				summary: fmt.Sprintf("+ assign %s -> %s", user.Email, role),
+				apply:   assign(user.Email, role),
+			})
+		}
+	}
+
+	return plan, nil
+}
+
+// applyPlan applies every change in a single transaction
+func applyPlan(ctx context.Context, db *sql.DB, plan []change) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, c := range plan {
+		if err := c.apply(ctx, tx); err != nil {
+			return fmt.Errorf("%s: %w", c.summary, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// expandPermissions resolves the "*" wildcards of the patterns against the known permissions
+func expandPermissions(patterns []string, known map[string]bool) ([]string, error) {
+	names := make([]string, 0, len(known))
+	for name := range known {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	seen := map[string]bool{}
+	var permissions []string
+	for _, pattern := range patterns {
+		matched := false
+		for _, name := range names {
+			ok, err := path.Match(pattern, name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid permission pattern %q: %w", pattern, err)
+			}
+			if !ok {
+				continue
+			}
+
+			matched = true
+			if !seen[name] {
+				seen[name] = true
+				permissions = append(permissions, name)
+			}
+		}
+
+		if !matched {
+			return nil, fmt.Errorf("permission %q matches no permission", pattern)
+		}
+	}
+
+	return permissions, nil
+}
+
+// upsert creates a role or permission, or updates its description
+func upsert(table, name, description string) func(ctx context.Context, tx *sql.Tx) error {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		query := `
+			INSERT INTO ` + table + ` (
+				name,
+				description
+			)
+			VALUES ($1, $2)
+			ON CONFLICT (name) DO UPDATE SET
+				description = EXCLUDED.description,
+				updated_at = NOW()`
+
+		_, err := tx.ExecContext(ctx, query, name, description)
+		return err
+	}
+}
+
+// grant attaches a permission to a role
+func grant(role, permission string) func(ctx context.Context, tx *sql.Tx) error {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		query := `
+			INSERT INTO role_permissions (
+				role_id,
+				permission_id
+			)
+			VALUES (
+				(SELECT uuid FROM roles WHERE name = $1),
+				(SELECT uuid FROM permissions WHERE name = $2)
+			)
+			ON CONFLICT DO NOTHING`
+
+		_, err := tx.ExecContext(ctx, query, role, permission)
+		return err
+	}
+}
+
+// createUser creates a verified user with the password of its environment variable
+func createUser(user SeedUser) func(ctx context.Context, tx *sql.Tx) error {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		password, err := resolvePassword(user)
+		if err != nil {
+			return err
+		}
+
+		if err := validator.ValidateUserPassword(password); err != nil {
+			return fmt.Errorf("password of %s: %w", user.Email, err)
+		}
+
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return err
+		}
+
+		query := `
+			INSERT INTO users (
+				name,
+				email,
+				password_hash,
+				is_email_verified,
+				is_blocked,
+				is_deleted
+			)
+			VALUES ($1, $2, $3, TRUE, FALSE, FALSE)
+			ON CONFLICT (email) DO NOTHING`
+
+		_, err = tx.ExecContext(ctx, query, user.Name, user.Email, string(hashedPassword))
+		return err
+	}
+}
+
+// assign assigns a role to a user
+func assign(email, role string) func(ctx context.Context, tx *sql.Tx) error {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		query := `
+			INSERT INTO user_roles (
+				user_id,
+				role_id
+			)
+			VALUES (
+				(SELECT uuid FROM users WHERE email = $1),
+				(SELECT uuid FROM roles WHERE name = $2)
+			)
+			ON CONFLICT DO NOTHING`
+
+		_, err := tx.ExecContext(ctx, query, email, role)
+		return err
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// testSeed is a small seed file with a wildcard grant and a bootstrap user
+func testSeed() *SeedFile {
+	return &SeedFile{
+		Permissions: []SeedPermission{
+			{Name: "read:user", Description: "Read users"},
+			{Name: "update:user", Description: "Update users"},
+		},
+		Roles: []SeedRole{
+			{Name: "admin", Description: "Administrator", Permissions: []string{"*"}},
+			{Name: "reader", Description: "Reader", Permissions: []string{"read:*"}},
+		},
+		Users: []SeedUser{
+			{Name: "root", Email: "root@mail.com", PasswordEnv: "SEED_TEST_PASSWORD", Roles: []string{"admin"}},
+		},
+	}
+}
+
+// emptyState is a database without any seeded row
+func emptyState() *state {
+	return &state{
+		permissions: map[string]string{},
+		roles:       map[string]string{},
+		grants:      map[[2]string]bool{},
+		users:       map[string]bool{},
+		userRoles:   map[[2]string]bool{},
+	}
+}
+
+// seededState is a database already holding the test seed
+func seededState() *state {
+	return &state{
+		permissions: map[string]string{"read:user": "Read users", "update:user": "Update users"},
+		roles:       map[string]string{"admin": "Administrator", "reader": "Reader"},
+		grants: map[[2]string]bool{
+			{"admin", "read:user"}:   true,
+			{"admin", "update:user"}: true,
+			{"reader", "read:user"}:  true,
+		},
+		users:     map[string]bool{"root@mail.com": true},
+		userRoles: map[[2]string]bool{{"root@mail.com", "admin"}: true},
+	}
+}
+
+func TestBuildPlan(t *testing.T) {
+	tests := []struct {
+		name    string
+		seed    func() *SeedFile
+		current func() *state
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "empty database",
+			seed:    testSeed,
+			current: emptyState,
+			want: []string{
+				"+ permission read:user",
+				"+ permission update:user",
+				"+ role admin",
+				"+ grant admin -> read:user",
+				"+ grant admin -> update:user",
+				"+ role reader",
+				"+ grant reader -> read:user",
+				"+ user root@mail.com (password from SEED_TEST_PASSWORD)",
+				"+ assign root@mail.com -> admin",
+			},
+		},
+		{
+			name:    "already seeded database",
+			seed:    testSeed,
+			current: seededState,
+			want:    nil,
+		},
+		{
+			name: "changed description",
+			seed: testSeed,
+			current: func() *state {
+				s := seededState()
+				s.roles["reader"] = "Old description"
+				return s
+			},
+			want: []string{`~ role reader: description "Old description" -> "Reader"`},
+		},
+		{
+			name: "rows created through the API are kept",
+			seed: testSeed,
+			current: func() *state {
+				s := seededState()
+				s.roles["support"] = "Support"
+				s.grants[[2]string{"support", "read:user"}] = true
+				return s
+			},
+			want: nil,
+		},
+		{
+			name: "wildcard matches a permission created through the API",
+			seed: testSeed,
+			current: func() *state {
+				s := seededState()
+				s.permissions["read:group"] = "Read groups"
+				return s
+			},
+			want: []string{
+				"+ grant admin -> read:group",
+				"+ grant reader -> read:group",
+			},
+		},
+		{
+			name: "unknown role of a user",
+			seed: func() *SeedFile {
+				seed := testSeed()
+				seed.Users[0].Roles = []string{"missing"}
+				return seed
+			},
+			current: emptyState,
+			wantErr: true,
+		},
+		{
+			name: "grant matching no permission",
+			seed: func() *SeedFile {
+				seed := testSeed()
+				seed.Roles[1].Permissions = []string{"delete:*"}
+				return seed
+			},
+			current: emptyState,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := buildPlan(tt.seed(), tt.current())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("buildPlan() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var got []string
+			for _, c := range plan {
+				got = append(got, c.summary)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("buildPlan() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolvePassword(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(file, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    string
+		wantErr bool
+	}{
+		{"variable", map[string]string{"SEED_TEST_PASSWORD": "from-env"}, "from-env", false},
+		{"file without trailing newline", map[string]string{"SEED_TEST_PASSWORD_FILE": file}, "from-file", false},
+		{"variable before file", map[string]string{"SEED_TEST_PASSWORD": "from-env", "SEED_TEST_PASSWORD_FILE": file}, "from-env", false},
+		{"missing", nil, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SEED_TEST_PASSWORD", "")
+			t.Setenv("SEED_TEST_PASSWORD_FILE", "")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			got, err := resolvePassword(SeedUser{Email: "root@mail.com", PasswordEnv: "SEED_TEST_PASSWORD"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolvePassword() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolvePassword() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
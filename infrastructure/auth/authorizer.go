@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"slices"
+
+	"github.com/edutav/licentia-usoris/internal/utils"
+)
+
+// PermissionResolver resolves the effective permissions of a user
+type PermissionResolver interface {
+	GetUserPermissions(ctx context.Context, userUUID string) ([]string, error)
+}
+
+// Authorizer struct
+type Authorizer struct {
+	resolver PermissionResolver
+}
+
+// NewAuthorizer creates a new authorizer
+func NewAuthorizer(resolver PermissionResolver) *Authorizer {
+	return &Authorizer{
+		resolver: resolver,
+	}
+}
+
+// EffectivePermissions resolves the current permissions of the principal.
+// The permissions carried by the access token are replaced by the ones in the database
+// so that grants and revocations apply before the token expires.
+func (a *Authorizer) EffectivePermissions(ctx context.Context, principal *Principal) ([]string, error) {
+	if principal.permissionsResolved {
+		return principal.Permissions, nil
+	}
+
+	permissions, err := a.resolver.GetUserPermissions(ctx, principal.UserUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	principal.Permissions = permissions
+	principal.permissionsResolved = true
+
+	return permissions, nil
+}
+
+// Authorize checks if the principal has every one of the permissions
+func (a *Authorizer) Authorize(ctx context.Context, principal *Principal, permissions ...string) error {
+	effective, err := a.EffectivePermissions(ctx, principal)
+	if err != nil {
+		return err
+	}
+
+	for _, permission := range permissions {
+		if !slices.Contains(effective, permission) {
+			return utils.ErrPermissionDenied
+		}
+	}
+
+	return nil
+}
@@ -3,15 +3,37 @@ package auth
 import (
 	"context"
 	"database/sql"
-	"log"
 	"time"
+
+	"github.com/edutav/licentia-usoris/infrastructure/logger"
 )
 
+// TokenBlacklist interface
+type TokenBlacklist interface {
+	// Add a token ID to the blacklist until it expires
+	Add(ctx context.Context, tokenID string, expiresAt time.Time) error
+
+	// Check if a token ID is blacklisted
+	IsBlacklisted(ctx context.Context, tokenID string) (bool, error)
+
+	// Spend a single-use token ID, adding it to the blacklist until it expires.
+	// Reports false when it was already spent, so only one of concurrent uses succeeds.
+	Spend(ctx context.Context, tokenID string, expiresAt time.Time) (bool, error)
+
+	// Revoke every token issued to the user before revokedAt, keeping the revocation until expiresAt
+	RevokeUser(ctx context.Context, userUUID string, revokedAt, expiresAt time.Time) error
+
+	// Check if the tokens issued to the user at issuedAt were revoked
+	IsUserRevoked(ctx context.Context, userUUID string, issuedAt time.Time) (bool, error)
+}
+
 // Blacklist struct
 type Blacklist struct {
 	db *sql.DB
 }
 
+var _ TokenBlacklist = (*Blacklist)(nil)
+
 // NewBlacklist creates a new blacklist
 func NewBlacklist(db *sql.DB) *Blacklist {
 	return &Blacklist{
@@ -20,35 +42,124 @@ func NewBlacklist(db *sql.DB) *Blacklist {
 }
 
 // Add adds a token to the blacklist
-func (b *Blacklist) Add(ctx context.Context, token string, expiresAt time.Time) (bool, error) {
-	query := `SELECT EXISTS (SELECT 1 FROM blacklisted_tokens WHERE token = $1 AND expires_at > NOW()`
-	var exists bool
-	err := b.db.QueryRowContext(ctx, query, token).Scan(&exists)
+func (b *Blacklist) Add(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	query := `
+		INSERT INTO blacklisted_tokens (
+			token,
+			expires_at
+		)
+		VALUES ($1, $2)
+		ON CONFLICT (token) DO NOTHING`
+
+	_, err := b.db.ExecContext(ctx, query, tokenID, expiresAt)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error adding token to blacklist")
+	}
+	return err
+}
+
+// Spend adds a single-use token to the blacklist in one insert, reporting whether it was not there yet
+func (b *Blacklist) Spend(ctx context.Context, tokenID string, expiresAt time.Time) (bool, error) {
+	query := `
+		INSERT INTO blacklisted_tokens (
+			token,
+			expires_at
+		)
+		VALUES ($1, $2)
+		ON CONFLICT (token) DO NOTHING`
+
+	result, err := b.db.ExecContext(ctx, query, tokenID, expiresAt)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error spending token")
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
 	if err != nil {
-		log.Printf("Erro ao verificar se o token está na blacklist: %v", err)
+		return false, err
 	}
-	return exists, err
+
+	return rows == 1, nil
 }
 
 // IsBlacklisted checks if a token is blacklisted
-func (b *Blacklist) IsBlacklisted(ctx context.Context, token string) (bool, error) {
-	query := `SELECT COUNT(*) FROM blacklist WHERE token = $1 AND expires_at > NOW()`
-	var count int
-	err := b.db.QueryRowContext(ctx, query, token).Scan(&count)
+func (b *Blacklist) IsBlacklisted(ctx context.Context, tokenID string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM blacklisted_tokens WHERE token = $1 AND expires_at > NOW())`
+	var exists bool
+	err := b.db.QueryRowContext(ctx, query, tokenID).Scan(&exists)
 	if err != nil {
-		log.Printf("Error checking if token is blacklisted: %v", err)
+		logger.FromContext(ctx).WithError(err).Error("Error checking if token is blacklisted")
 		return false, err
 	}
 
-	return count > 0, nil
+	return exists, nil
 }
 
-// Remove removes a token from the blacklist
-func (b *Blacklist) Remove(ctx context.Context) error {
-	query := `DELETE FROM blacklisted_tokens WHERE expires_at <= NOW()`
-	_, err := b.db.ExecContext(ctx, query)
+// RevokeUser revokes every token issued to the user before revokedAt.
+// The time comes from the caller, as the iat claims come from the clock of the service.
+func (b *Blacklist) RevokeUser(ctx context.Context, userUUID string, revokedAt, expiresAt time.Time) error {
+	query := `
+		INSERT INTO user_token_revocations (
+			user_id,
+			revoked_at,
+			expires_at
+		)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET
+			revoked_at = GREATEST(user_token_revocations.revoked_at, EXCLUDED.revoked_at),
+			expires_at = GREATEST(user_token_revocations.expires_at, EXCLUDED.expires_at)`
+
+	_, err := b.db.ExecContext(ctx, query, userUUID, revokedAt, expiresAt)
 	if err != nil {
-		log.Printf("Error removing token from blacklist: %v", err)
+		logger.FromContext(ctx).WithError(err).Error("Error revoking user tokens")
 	}
 	return err
 }
+
+// IsUserRevoked checks if the tokens issued to the user at issuedAt were revoked,
+// that is issued strictly before the revocation time
+func (b *Blacklist) IsUserRevoked(ctx context.Context, userUUID string, issuedAt time.Time) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM user_token_revocations
+			WHERE user_id = $1 AND revoked_at > $2 AND expires_at > NOW()
+		)`
+
+	var exists bool
+	err := b.db.QueryRowContext(ctx, query, userUUID, issuedAt).Scan(&exists)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error checking if user tokens are revoked")
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// Remove removes the expired tokens and user revocations from the blacklist, returning how many were removed
+func (b *Blacklist) Remove(ctx context.Context) (int64, error) {
+	query := `DELETE FROM blacklisted_tokens WHERE expires_at <= NOW()`
+	result, err := b.db.ExecContext(ctx, query)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error removing token from blacklist")
+		return 0, err
+	}
+
+	tokens, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	query = `DELETE FROM user_token_revocations WHERE expires_at <= NOW()`
+	result, err = b.db.ExecContext(ctx, query)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error removing user revocations from blacklist")
+		return tokens, err
+	}
+
+	revocations, err := result.RowsAffected()
+	if err != nil {
+		return tokens, err
+	}
+
+	return tokens + revocations, nil
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+
+	"github.com/edutav/licentia-usoris/internal/utils"
+)
+
+// SecretCipher encrypts the secrets stored in the database with AES-256-GCM
+type SecretCipher struct {
+	aead cipher.AEAD
+}
+
+// NewSecretCipher creates a cipher from a base64 encoded 32 bytes key
+func NewSecretCipher(encodedKey string) (*SecretCipher, error) {
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil || len(key) != 32 {
+		return nil, utils.ErrInvalidEncryptionKey
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, utils.ErrInvalidEncryptionKey
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, utils.ErrInvalidEncryptionKey
+	}
+
+	return &SecretCipher{
+		aead: aead,
+	}, nil
+}
+
+// Encrypt encrypts the secret bound to the associated data, such as the UUID of its owner.
+// The result is the base64 of the nonce followed by the ciphertext.
+func (c *SecretCipher) Encrypt(secret, associatedData string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := c.aead.Seal(nonce, nonce, []byte(secret), []byte(associatedData))
+
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a secret encrypted with the same associated data
+func (c *SecretCipher) Decrypt(encrypted, associatedData string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return "", utils.ErrDecryptSecret
+	}
+
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+
+	secret, err := c.aead.Open(nil, nonce, ciphertext, []byte(associatedData))
+	if err != nil {
+		return "", utils.ErrDecryptSecret
+	}
+
+	return string(secret), nil
+}
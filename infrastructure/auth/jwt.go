@@ -1 +1,207 @@
 package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/edutav/licentia-usoris/internal/utils"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Default lifetime of an access token when none is configured
+const defaultAccessTokenTTL = 15 * time.Minute
+
+// Clock skew accepted on the time claims. It also covers the iat claim of a token
+// moved up to the next second to outlive a revocation, see GenerateAccessToken.
+const clockSkew = time.Second
+
+// Token types carried in the token_type claim
+const (
+	TokenTypeAccess = "access"
+	TokenTypeMFA    = "mfa_challenge"
+)
+
+// Claims are the claims carried by the tokens
+type Claims struct {
+	TokenType   string   `json:"token_type"`
+	Email       string   `json:"email,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Token is a signed token and its metadata
+type Token struct {
+	Value     string
+	ID        string
+	ExpiresAt time.Time
+}
+
+// TokenService interface
+type TokenService interface {
+	// Generate a signed access token for the user, issued no earlier than minIssuedAt
+	GenerateAccessToken(userUUID, email string, roles, permissions []string, minIssuedAt time.Time) (*Token, error)
+
+	// Parse and verify an access token
+	ParseAccessToken(token string) (*Claims, error)
+
+	// Lifetime of the access tokens
+	AccessTokenTTL() time.Duration
+
+	// Generate a signed token proving the user passed the first login step
+	GenerateMFAToken(userUUID string, ttl time.Duration) (*Token, error)
+
+	// Parse and verify an MFA challenge token
+	ParseMFAToken(token string) (*Claims, error)
+}
+
+// JWTService struct
+type JWTService struct {
+	secret         []byte
+	issuer         string
+	audience       []string
+	accessTokenTTL time.Duration
+}
+
+var _ TokenService = (*JWTService)(nil)
+
+// NewJWTService creates a new JWT service signing tokens with HMAC-SHA256
+func NewJWTService(secret, issuer string, audience []string, accessTokenTTL time.Duration) (*JWTService, error) {
+	if secret == "" {
+		return nil, utils.ErrMissingJWTSecret
+	}
+
+	if accessTokenTTL <= 0 {
+		accessTokenTTL = defaultAccessTokenTTL
+	}
+
+	return &JWTService{
+		secret:         []byte(secret),
+		issuer:         issuer,
+		audience:       audience,
+		accessTokenTTL: accessTokenTTL,
+	}, nil
+}
+
+// GenerateAccessToken generates a signed access token for the user.
+// The iat claim keeps whole seconds: a minIssuedAt up to a second ahead lets a token issued
+// right after a revocation of the user's tokens carry an iat the revocation does not reach.
+func (s *JWTService) GenerateAccessToken(
+	userUUID, email string, roles, permissions []string, minIssuedAt time.Time,
+) (*Token, error) {
+	return s.generate(Claims{
+		TokenType:   TokenTypeAccess,
+		Email:       email,
+		Roles:       roles,
+		Permissions: permissions,
+	}, userUUID, s.accessTokenTTL, minIssuedAt)
+}
+
+// ParseAccessToken parses an access token and verifies its signature, expiry, issuer and audience
+func (s *JWTService) ParseAccessToken(token string) (*Claims, error) {
+	return s.parse(token, TokenTypeAccess)
+}
+
+// AccessTokenTTL returns the lifetime of the access tokens
+func (s *JWTService) AccessTokenTTL() time.Duration {
+	return s.accessTokenTTL
+}
+
+// GenerateMFAToken generates a signed token proving the user passed the first login step.
+// It cannot be used as an access token.
+func (s *JWTService) GenerateMFAToken(userUUID string, ttl time.Duration) (*Token, error) {
+	return s.generate(Claims{
+		TokenType: TokenTypeMFA,
+	}, userUUID, ttl, time.Time{})
+}
+
+// ParseMFAToken parses an MFA challenge token and verifies its signature, expiry, issuer and audience
+func (s *JWTService) ParseMFAToken(token string) (*Claims, error) {
+	return s.parse(token, TokenTypeMFA)
+}
+
+// generate fills the registered claims and signs the token
+func (s *JWTService) generate(claims Claims, userUUID string, ttl time.Duration, minIssuedAt time.Time) (*Token, error) {
+	id, err := newTokenID()
+	if err != nil {
+		return nil, utils.ErrGenerateJWTTokenWithRole
+	}
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(ttl)
+
+	issuedAt := now
+	if minIssuedAt.After(issuedAt) {
+		issuedAt = minIssuedAt.UTC()
+	}
+
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        id,
+		Subject:   userUUID,
+		Issuer:    s.issuer,
+		Audience:  s.audience,
+		IssuedAt:  jwt.NewNumericDate(issuedAt),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	if err != nil {
+		return nil, utils.ErrGenerateJWTTokenWithRole
+	}
+
+	return &Token{
+		Value:     signed,
+		ID:        id,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// parse verifies the token signature, expiry, issuer, audience and type, and requires the iat claim
+func (s *JWTService) parse(token, tokenType string) (*Claims, error) {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+	}
+	if s.issuer != "" {
+		options = append(options, jwt.WithIssuer(s.issuer))
+	}
+
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	}, options...)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, utils.ErrTokenExpired
+		}
+		return nil, utils.ErrInvalidToken
+	}
+
+	if claims.TokenType != tokenType || claims.Subject == "" || claims.ID == "" || claims.IssuedAt == nil {
+		return nil, utils.ErrInvalidToken
+	}
+
+	// The token must be addressed to at least one of the configured audiences
+	if len(s.audience) > 0 && !slices.ContainsFunc(claims.Audience, func(aud string) bool {
+		return slices.Contains(s.audience, aud)
+	}) {
+		return nil, utils.ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+// newTokenID generates a random token identifier used as the jti claim
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGenerateAccessTokenMinIssuedAt(t *testing.T) {
+	service, err := NewJWTService("test-secret", "test", []string{"api"}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+	nextSecond := now.Truncate(time.Second).Add(time.Second)
+
+	tests := []struct {
+		name        string
+		minIssuedAt time.Time
+		want        time.Time
+	}{
+		{"issued now", time.Time{}, now.Truncate(time.Second)},
+		{"issued at a past minimum", now.Add(-time.Hour), now.Truncate(time.Second)},
+		{"issued at the next second", nextSecond, nextSecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := service.GenerateAccessToken("user-1", "user@example.com", nil, nil, tt.minIssuedAt)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// A token issued up to a second ahead is accepted right away
+			claims, err := service.ParseAccessToken(token.Value)
+			if err != nil {
+				t.Fatalf("ParseAccessToken() error = %v", err)
+			}
+
+			// The generation may cross into the next second
+			got := claims.IssuedAt.Time
+			if got.Before(tt.want) || got.After(tt.want.Add(time.Second)) {
+				t.Errorf("iat = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
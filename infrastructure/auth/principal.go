@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// principalKey is the context key of the authenticated principal
+type principalKey struct{}
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	UserUUID    string
+	Email       string
+	Roles       []string
+	Permissions []string
+	TokenID     string
+	ExpiresAt   time.Time
+
+	// Set once the permissions have been resolved from the database
+	permissionsResolved bool
+}
+
+// NewPrincipal creates a principal from the claims of an access token
+func NewPrincipal(claims *Claims) *Principal {
+	principal := &Principal{
+		UserUUID:    claims.Subject,
+		Email:       claims.Email,
+		Roles:       claims.Roles,
+		Permissions: claims.Permissions,
+		TokenID:     claims.ID,
+	}
+
+	if claims.ExpiresAt != nil {
+		principal.ExpiresAt = claims.ExpiresAt.Time
+	}
+
+	return principal
+}
+
+// HasRole checks if the principal has the role
+func (p *Principal) HasRole(role string) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+// HasPermission checks if the principal has the permission
+func (p *Principal) HasPermission(permission string) bool {
+	return slices.Contains(p.Permissions, permission)
+}
+
+// WithPrincipal returns a copy of the context carrying the principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext gets the principal carried by the context
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
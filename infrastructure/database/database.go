@@ -3,9 +3,11 @@ package database
 import (
 	"database/sql"
 	"fmt"
-	"log"
 
+	"github.com/XSAM/otelsql"
+	"github.com/edutav/licentia-usoris/infrastructure/logger"
 	_ "github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
 func NewConnectionPostgres(host, port, user, password, name string) (*sql.DB, error) {
@@ -13,7 +15,15 @@ func NewConnectionPostgres(host, port, user, password, name string) (*sql.DB, er
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", host, port, user, password, name,
 	)
 
-	db, err := sql.Open("postgres", connSTR)
+	// Every statement is traced as a child of the span of its context
+	db, err := otelsql.Open("postgres", connSTR,
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitConnPrepare:      true,
+			OmitRows:             true,
+		}),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -36,9 +46,9 @@ func NewConnectionPostgres(host, port, user, password, name string) (*sql.DB, er
 	var timezone string
 	err = db.QueryRow("SHOW TIME ZONE").Scan(&timezone)
 	if err != nil {
-		log.Printf("Warning: Error getting timezone: %s", err)
+		logger.Base().WithError(err).Warn("Error getting database timezone")
 	} else if timezone != "UTC" {
-		log.Printf("Warning: Timezone is not UTC: %s", timezone)
+		logger.Base().WithField("timezone", timezone).Warn("Database timezone is not UTC")
 	}
 
 	return db, nil
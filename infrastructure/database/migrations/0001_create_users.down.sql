@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS pre_registrations;
+DROP TABLE IF EXISTS users;
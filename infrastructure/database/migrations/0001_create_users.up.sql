@@ -0,0 +1,34 @@
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+CREATE TABLE users (
+    uuid              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name              VARCHAR(200) NOT NULL,
+    email             VARCHAR(255) NOT NULL,
+    password_hash     TEXT NOT NULL,
+    date_of_birth     DATE,
+    phone_number      VARCHAR(20),
+    is_blocked        BOOLEAN NOT NULL DEFAULT FALSE,
+    is_email_verified BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at        TIMESTAMPTZ,
+    is_deleted        BOOLEAN NOT NULL DEFAULT FALSE,
+    last_login        TIMESTAMPTZ,
+    blocked_at        TIMESTAMPTZ,
+    blocked_reason    VARCHAR(500),
+    CONSTRAINT users_email_key UNIQUE (email)
+);
+
+CREATE TABLE pre_registrations (
+    uuid          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    email         VARCHAR(255) NOT NULL,
+    password_hash TEXT NOT NULL,
+    code_otp      TEXT NOT NULL,
+    user_data     JSONB NOT NULL,
+    expires_at    TIMESTAMPTZ NOT NULL,
+    is_verified   BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT pre_registrations_email_key UNIQUE (email)
+);
+
+CREATE INDEX pre_registrations_expires_at_idx ON pre_registrations (expires_at);
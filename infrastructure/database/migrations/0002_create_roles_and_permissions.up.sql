@@ -0,0 +1,33 @@
+CREATE TABLE roles (
+    uuid        UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name        VARCHAR(50) NOT NULL,
+    description TEXT,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT roles_name_key UNIQUE (name)
+);
+
+CREATE TABLE permissions (
+    uuid        UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name        VARCHAR(100) NOT NULL,
+    description TEXT,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT permissions_name_key UNIQUE (name)
+);
+
+CREATE TABLE role_permissions (
+    role_id       UUID NOT NULL REFERENCES roles (uuid) ON DELETE CASCADE,
+    permission_id UUID NOT NULL REFERENCES permissions (uuid) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE INDEX role_permissions_permission_id_idx ON role_permissions (permission_id);
+
+CREATE TABLE user_roles (
+    user_id UUID NOT NULL REFERENCES users (uuid) ON DELETE CASCADE,
+    role_id UUID NOT NULL REFERENCES roles (uuid) ON DELETE CASCADE,
+    PRIMARY KEY (user_id, role_id)
+);
+
+CREATE INDEX user_roles_role_id_idx ON user_roles (role_id);
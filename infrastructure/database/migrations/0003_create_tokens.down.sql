@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS user_token_revocations;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS blacklisted_tokens;
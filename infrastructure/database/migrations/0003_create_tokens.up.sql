@@ -0,0 +1,30 @@
+CREATE TABLE blacklisted_tokens (
+    token      VARCHAR(64) PRIMARY KEY,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX blacklisted_tokens_expires_at_idx ON blacklisted_tokens (expires_at);
+
+CREATE TABLE refresh_tokens (
+    uuid        UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id     UUID NOT NULL REFERENCES users (uuid) ON DELETE CASCADE,
+    family_id   UUID NOT NULL,
+    token_hash  CHAR(64) NOT NULL,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    rotated_at  TIMESTAMPTZ,
+    revoked_at  TIMESTAMPTZ,
+    replaced_by UUID,
+    CONSTRAINT refresh_tokens_token_hash_key UNIQUE (token_hash)
+);
+
+CREATE INDEX refresh_tokens_family_id_idx ON refresh_tokens (family_id);
+CREATE INDEX refresh_tokens_user_id_idx ON refresh_tokens (user_id);
+
+CREATE TABLE user_token_revocations (
+    user_id    UUID PRIMARY KEY REFERENCES users (uuid) ON DELETE CASCADE,
+    revoked_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX user_token_revocations_expires_at_idx ON user_token_revocations (expires_at);
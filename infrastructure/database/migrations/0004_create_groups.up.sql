@@ -0,0 +1,24 @@
+CREATE TABLE groups (
+    uuid        UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name        VARCHAR(50) NOT NULL,
+    description TEXT,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT groups_name_key UNIQUE (name)
+);
+
+CREATE TABLE group_members (
+    group_id UUID NOT NULL REFERENCES groups (uuid) ON DELETE CASCADE,
+    user_id  UUID NOT NULL REFERENCES users (uuid) ON DELETE CASCADE,
+    PRIMARY KEY (group_id, user_id)
+);
+
+CREATE INDEX group_members_user_id_idx ON group_members (user_id);
+
+CREATE TABLE group_roles (
+    group_id UUID NOT NULL REFERENCES groups (uuid) ON DELETE CASCADE,
+    role_id  UUID NOT NULL REFERENCES roles (uuid) ON DELETE CASCADE,
+    PRIMARY KEY (group_id, role_id)
+);
+
+CREATE INDEX group_roles_role_id_idx ON group_roles (role_id);
@@ -0,0 +1,8 @@
+CREATE TABLE password_history (
+    uuid          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id       UUID NOT NULL REFERENCES users (uuid) ON DELETE CASCADE,
+    password_hash TEXT NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX password_history_user_id_created_at_idx ON password_history (user_id, created_at DESC);
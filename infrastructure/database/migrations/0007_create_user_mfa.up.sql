@@ -0,0 +1,8 @@
+CREATE TABLE user_mfa (
+    user_id          UUID PRIMARY KEY REFERENCES users (uuid) ON DELETE CASCADE,
+    secret_encrypted TEXT NOT NULL,
+    enabled_at       TIMESTAMPTZ,
+    last_used_step   BIGINT NOT NULL DEFAULT 0,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
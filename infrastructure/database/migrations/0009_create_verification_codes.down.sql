@@ -0,0 +1,3 @@
+ALTER TABLE pre_registrations ADD COLUMN code_otp TEXT NOT NULL DEFAULT '';
+
+DROP TABLE IF EXISTS verification_codes;
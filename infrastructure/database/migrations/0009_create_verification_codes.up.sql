@@ -0,0 +1,16 @@
+CREATE TABLE verification_codes (
+    uuid        UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    purpose     VARCHAR(50) NOT NULL,
+    subject     VARCHAR(255) NOT NULL,
+    code_hash   CHAR(64) NOT NULL,
+    attempts    INTEGER NOT NULL DEFAULT 0,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    consumed_at TIMESTAMPTZ,
+    CONSTRAINT verification_codes_purpose_subject_key UNIQUE (purpose, subject)
+);
+
+CREATE INDEX verification_codes_expires_at_idx ON verification_codes (expires_at);
+
+-- The codes of the pre-registrations now live in verification_codes
+ALTER TABLE pre_registrations DROP COLUMN code_otp;
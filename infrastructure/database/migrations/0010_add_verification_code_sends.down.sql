@@ -0,0 +1,3 @@
+ALTER TABLE verification_codes
+    DROP COLUMN IF EXISTS send_window_started_at,
+    DROP COLUMN IF EXISTS send_count;
@@ -0,0 +1,4 @@
+-- Sends of the code of a purpose and subject in the current day window, so resends can be capped
+ALTER TABLE verification_codes
+    ADD COLUMN send_count INTEGER NOT NULL DEFAULT 1,
+    ADD COLUMN send_window_started_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
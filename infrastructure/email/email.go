@@ -1,20 +1,44 @@
 package email
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"html"
+	"sync"
 
+	"github.com/edutav/licentia-usoris/infrastructure/logger"
+	"github.com/edutav/licentia-usoris/infrastructure/metrics"
+	"github.com/edutav/licentia-usoris/infrastructure/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"gopkg.in/gomail.v2"
 )
 
+// Returned when sending after the sender was closed
+var ErrSenderClosed = errors.New("email sender closed")
+
 // Email interface
 type EmailSender interface {
-	SendOTP(to string, otp string) error
+	SendOTP(ctx context.Context, to string, otp string) error
+	SendPasswordReset(ctx context.Context, to string, resetLink string) error
+	SendPasswordChanged(ctx context.Context, to string) error
+
+	// Run send in the background, with a context that outlives the request.
+	// Close waits for it, and the send methods log their own failures.
+	Background(ctx context.Context, send func(ctx context.Context) error)
 }
 
+// backgroundKey marks the context of a send already counted as in flight by Background
+type backgroundKey struct{}
+
 // Sender struct
 type Sender struct {
 	dialer *gomail.Dialer
+
+	mu       sync.Mutex
+	closed   bool
+	inFlight sync.WaitGroup
 }
 
 var _ EmailSender = (*Sender)(nil)
@@ -27,19 +51,146 @@ func NewEmailSender(host string, port int, username string, password string) *Se
 }
 
 // SendOTP sends an OTP to the user
-func (s *Sender) SendOTP(to string, otp string) error {
+func (s *Sender) SendOTP(ctx context.Context, to string, otp string) error {
 	m := gomail.NewMessage()
 	m.SetHeader("From", "noreply@localhost.com")
 	m.SetHeader("To", to)
 	m.SetHeader("Subject", "Your OTP")
 	m.SetBody("text/html", fmt.Sprintf("Your OTP is: %s", otp))
 
-	if err := s.dialer.DialAndSend(m); err != nil {
-		log.Printf("Failed to send OTP email to %s: %v", to, err)
+	if err := s.dialAndSend(ctx, m, "otp"); err != nil {
+		logger.FromContext(ctx).WithError(err).WithField("to", logger.RedactEmail(to)).Error("Failed to send OTP email")
+		return err
+	}
+
+	logger.FromContext(ctx).WithField("to", logger.RedactEmail(to)).Info("OTP email sent")
+
+	return nil
+}
+
+// SendPasswordReset sends the link to reset the password of the user
+func (s *Sender) SendPasswordReset(ctx context.Context, to string, resetLink string) error {
+	m := gomail.NewMessage()
+	m.SetHeader("From", "noreply@localhost.com")
+	m.SetHeader("To", to)
+	m.SetHeader("Subject", "Reset your password")
+	m.SetBody("text/html", fmt.Sprintf(
+		"We received a request to reset your password. Use the link below to choose a new one:<br>"+
+			"<a href=\"%[1]s\">%[1]s</a><br>"+
+			"If you did not request it, you can ignore this email.",
+		html.EscapeString(resetLink),
+	))
+
+	if err := s.dialAndSend(ctx, m, "password_reset"); err != nil {
+		logger.FromContext(ctx).WithError(err).WithField("to", logger.RedactEmail(to)).Error("Failed to send password reset email")
+		return err
+	}
+
+	logger.FromContext(ctx).WithField("to", logger.RedactEmail(to)).Info("Password reset email sent")
+
+	return nil
+}
+
+// SendPasswordChanged notifies the user that its password was changed
+func (s *Sender) SendPasswordChanged(ctx context.Context, to string) error {
+	m := gomail.NewMessage()
+	m.SetHeader("From", "noreply@localhost.com")
+	m.SetHeader("To", to)
+	m.SetHeader("Subject", "Your password was changed")
+	m.SetBody("text/html",
+		"The password of your account was changed and every other session was signed out.<br>"+
+			"If you did not change it, reset your password and contact support immediately.",
+	)
+
+	if err := s.dialAndSend(ctx, m, "password_changed"); err != nil {
+		logger.FromContext(ctx).WithError(err).WithField("to", logger.RedactEmail(to)).Error("Failed to send password changed email")
 		return err
 	}
 
-	log.Printf("OTP email sent to %s successfully", to)
+	logger.FromContext(ctx).WithField("to", logger.RedactEmail(to)).Info("Password changed email sent")
 
 	return nil
 }
+
+// Close refuses new emails and waits for the ones being sent, or for the context to be done
+func (s *Sender) Close(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("closing email sender: %w", ctx.Err())
+	}
+}
+
+// Background implements EmailSender. A send started before Close is still delivered.
+func (s *Sender) Background(ctx context.Context, send func(ctx context.Context) error) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		logger.FromContext(ctx).WithError(ErrSenderClosed).Error("Failed to queue email")
+		return
+	}
+	s.inFlight.Add(1)
+	s.mu.Unlock()
+
+	ctx = context.WithValue(context.WithoutCancel(ctx), backgroundKey{}, true)
+	go func() {
+		defer s.inFlight.Done()
+		_ = send(ctx)
+	}()
+}
+
+// dialAndSend sends the message unless the sender was closed, counting and tracing it by kind
+func (s *Sender) dialAndSend(ctx context.Context, m *gomail.Message, kind string) (err error) {
+	_, span := tracing.Start(ctx, "email.send", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("email.kind", kind)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	// A background send was counted when it was queued
+	if ctx.Value(backgroundKey{}) == nil {
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			metrics.Emails.WithLabelValues(kind, metrics.ResultFailure).Inc()
+			return ErrSenderClosed
+		}
+		s.inFlight.Add(1)
+		s.mu.Unlock()
+		defer s.inFlight.Done()
+	}
+
+	err = s.dialer.DialAndSend(m)
+	metrics.Emails.WithLabelValues(kind, metrics.Result(err)).Inc()
+
+	return err
+}
+
+// Ping dials and authenticates to the SMTP server, or fails when the context is done first
+func (s *Sender) Ping(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		conn, err := s.dialer.Dial()
+		if err == nil {
+			err = conn.Close()
+		}
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
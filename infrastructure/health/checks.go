@@ -0,0 +1,71 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/edutav/licentia-usoris/infrastructure/database"
+	"github.com/edutav/licentia-usoris/infrastructure/email"
+)
+
+// PostgresCheck pings the connection pool and reports its stats
+func PostgresCheck(db *sql.DB) Check {
+	return Check{
+		Name: "postgres",
+		Run: func(ctx context.Context) (interface{}, error) {
+			err := db.PingContext(ctx)
+			stats := db.Stats()
+			return map[string]int{
+				"open_connections": stats.OpenConnections,
+				"in_use":           stats.InUse,
+				"idle":             stats.Idle,
+			}, err
+		},
+	}
+}
+
+// MigrationsCheck reports the applied migrations, failing while some are pending
+func MigrationsCheck(migrator *database.Migrator) Check {
+	return Check{
+		Name: "migrations",
+		Run: func(ctx context.Context) (interface{}, error) {
+			status, err := migrator.Status(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			var version int64
+			applied, pending := 0, 0
+			for _, migration := range status {
+				if migration.Applied() {
+					applied++
+					version = migration.Version
+				} else {
+					pending++
+				}
+			}
+
+			details := map[string]int64{
+				"version": version,
+				"applied": int64(applied),
+				"pending": int64(pending),
+			}
+			if pending > 0 {
+				return details, fmt.Errorf("%d pending migrations", pending)
+			}
+
+			return details, nil
+		},
+	}
+}
+
+// SMTPCheck dials the SMTP server
+func SMTPCheck(sender *email.Sender) Check {
+	return Check{
+		Name: "smtp",
+		Run: func(ctx context.Context) (interface{}, error) {
+			return nil, sender.Ping(ctx)
+		},
+	}
+}
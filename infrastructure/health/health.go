@@ -0,0 +1,115 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Status of a check or of the whole report
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Check is a dependency the service needs to serve traffic
+type Check struct {
+	Name string
+
+	// Run checks the dependency, returning details to report along its status
+	Run func(ctx context.Context) (interface{}, error)
+}
+
+// CheckResult is the outcome of a check
+type CheckResult struct {
+	Name      string      `json:"name" example:"postgres"`
+	Status    string      `json:"status" example:"up"`
+	LatencyMS float64     `json:"latency_ms" example:"1.25"`
+	Error     string      `json:"error,omitempty"`
+	Details   interface{} `json:"details,omitempty"`
+}
+
+// Report is the outcome of every check. It is up only when every check is up
+// and the service is not shutting down.
+type Report struct {
+	Status       string        `json:"status" example:"up"`
+	ShuttingDown bool          `json:"shutting_down"`
+	Checks       []CheckResult `json:"checks"`
+}
+
+// Readiness runs the checks telling whether the service can serve traffic
+type Readiness struct {
+	checks       []Check
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+// NewReadiness creates a readiness of the checks, each given the timeout to finish
+func NewReadiness(timeout time.Duration, checks ...Check) *Readiness {
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	return &Readiness{
+		checks:  checks,
+		timeout: timeout,
+	}
+}
+
+// SetShuttingDown makes the service unready, so traffic stops being routed to it before it stops
+func (r *Readiness) SetShuttingDown() {
+	r.shuttingDown.Store(true)
+}
+
+// Check runs the checks concurrently and reports their outcome
+func (r *Readiness) Check(ctx context.Context) *Report {
+	report := &Report{
+		Status:       StatusUp,
+		ShuttingDown: r.shuttingDown.Load(),
+		Checks:       make([]CheckResult, len(r.checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range r.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			report.Checks[i] = r.run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	if report.ShuttingDown {
+		report.Status = StatusDown
+	}
+	for _, result := range report.Checks {
+		if result.Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+
+	return report
+}
+
+// run runs the check within the timeout
+func (r *Readiness) run(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	details, err := check.Run(ctx)
+	latency := time.Since(start)
+
+	result := CheckResult{
+		Name:      check.Name,
+		Status:    StatusUp,
+		LatencyMS: float64(latency.Microseconds()) / 1000,
+		Details:   details,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
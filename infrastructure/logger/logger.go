@@ -0,0 +1,110 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/edutav/licentia-usoris/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+type contextKey struct{}
+
+// Base logger, used when the context carries none
+var base = logrus.NewEntry(newLogger(os.Stderr, logrus.InfoLevel, &logrus.JSONFormatter{}))
+
+// Setup configures the base logger from the config and sends the standard library logs through it
+func Setup(cfg config.LogConfig) error {
+	level := logrus.InfoLevel
+	if cfg.Level != "" {
+		parsed, err := logrus.ParseLevel(cfg.Level)
+		if err != nil {
+			return err
+		}
+		level = parsed
+	}
+
+	var formatter logrus.Formatter = &logrus.JSONFormatter{}
+	if cfg.Format == "text" {
+		formatter = &logrus.TextFormatter{FullTimestamp: true}
+	}
+
+	base = logrus.NewEntry(newLogger(os.Stdout, level, formatter))
+
+	// The packages still logging with the standard library log at info level
+	log.SetFlags(0)
+	log.SetOutput(base.WriterLevel(logrus.InfoLevel))
+
+	return nil
+}
+
+// newLogger creates a logger redacting the emails of its entries
+func newLogger(out io.Writer, level logrus.Level, formatter logrus.Formatter) *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(out)
+	logger.SetLevel(level)
+	logger.SetFormatter(formatter)
+	logger.AddHook(redactHook{})
+	return logger
+}
+
+// WithContext returns a copy of the context carrying the logger
+func WithContext(ctx context.Context, entry *logrus.Entry) context.Context {
+	return context.WithValue(ctx, contextKey{}, entry)
+}
+
+// FromContext gets the logger of the context, the base logger when it carries none
+func FromContext(ctx context.Context) *logrus.Entry {
+	if entry, ok := ctx.Value(contextKey{}).(*logrus.Entry); ok {
+		return entry
+	}
+	return base
+}
+
+// Base gets the base logger, for the code running outside a request
+func Base() *logrus.Entry {
+	return base
+}
+
+var emailRegex = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// RedactEmail keeps the first letter of the local part and the domain of an email
+func RedactEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return "***"
+	}
+	return email[:1] + "***" + email[at:]
+}
+
+// redactText redacts every email in the text
+func redactText(text string) string {
+	return emailRegex.ReplaceAllStringFunc(text, RedactEmail)
+}
+
+// redactHook redacts the emails in the message and the string fields of every entry
+type redactHook struct{}
+
+func (redactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (redactHook) Fire(entry *logrus.Entry) error {
+	entry.Message = redactText(entry.Message)
+
+	// The entry fired is a copy, so its fields can be changed
+	for key, value := range entry.Data {
+		switch value := value.(type) {
+		case string:
+			entry.Data[key] = redactText(value)
+		case error:
+			entry.Data[key] = redactText(value.Error())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace of the metrics
+const namespace = "licentia_usoris"
+
+// Results of the counted operations
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+var (
+	// HTTP requests by route template, method and status
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route, method and status.",
+	}, []string{"route", "method", "status"})
+
+	// Latency of the HTTP requests by route template and method
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of the HTTP requests by route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	// Pre-registrations saved and their code sent
+	RegistrationsStarted = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "registrations_started_total",
+		Help:      "Pre-registrations saved and their OTP code sent.",
+	})
+
+	// Users created from a verified pre-registration
+	RegistrationsCompleted = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "registrations_completed_total",
+		Help:      "Users created from a verified pre-registration.",
+	})
+
+	// Refused OTP codes by reason
+	OTPFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "otp_failures_total",
+		Help:      "Refused OTP codes by reason.",
+	}, []string{"reason"})
+
+	// Login attempts by result: success, failure, or mfa_challenge when the login waits for a second factor
+	Logins = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Login attempts by result.",
+	}, []string{"result"})
+
+	// Emails by kind and result
+	Emails = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "emails_total",
+		Help:      "Emails sent by kind and result.",
+	}, []string{"kind", "result"})
+
+	// Runs of the maintenance jobs by job and result, skipped when another replica ran it
+	JobRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "job_runs_total",
+		Help:      "Runs of the maintenance jobs by job and result.",
+	}, []string{"job", "result"})
+
+	// Latency of the maintenance jobs by job
+	JobDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "job_duration_seconds",
+		Help:      "Latency of the maintenance jobs by job.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"job"})
+)
+
+// Result labels a count by whether err is nil
+func Result(err error) string {
+	if err != nil {
+		return ResultFailure
+	}
+	return ResultSuccess
+}
+
+// RegisterDBStats exposes the stats of the connection pool
+func RegisterDBStats(db *sql.DB, name string) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
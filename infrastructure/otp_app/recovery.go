@@ -0,0 +1,48 @@
+package otpapp
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+)
+
+// Letters and digits of the recovery codes, without the ones easily mistaken for each other
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// Characters of a recovery code, shown as two groups of five
+const recoveryCodeLength = 10
+
+// GenerateRecoveryCodes generates count random recovery codes formatted as xxxxx-xxxxx
+func GenerateRecoveryCodes(count int) ([]string, error) {
+	codes := make([]string, 0, count)
+	max := big.NewInt(int64(len(recoveryCodeAlphabet)))
+
+	for len(codes) < count {
+		var code strings.Builder
+		for i := 0; i < recoveryCodeLength; i++ {
+			if i == recoveryCodeLength/2 {
+				code.WriteByte('-')
+			}
+
+			n, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				return nil, err
+			}
+			code.WriteByte(recoveryCodeAlphabet[n.Int64()])
+		}
+		codes = append(codes, code.String())
+	}
+
+	return codes, nil
+}
+
+// NormalizeRecoveryCode lowercases the code and removes its separators and spaces,
+// so it matches however the user typed it
+func NormalizeRecoveryCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(code))
+}
@@ -0,0 +1,93 @@
+package otpapp
+
+import (
+	"bytes"
+	"image/png"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/hotp"
+	"github.com/pquerna/otp/totp"
+)
+
+const (
+	// Seconds each TOTP code is valid for
+	totpPeriod = 30
+
+	// Time steps accepted before and after the current one, for clock drift
+	totpSkew = 1
+
+	// Size in pixels of the enrollment QR code
+	qrCodeSize = 256
+)
+
+// TOTPKey is a new authenticator app secret and the ways to enroll it
+type TOTPKey struct {
+	Secret string
+	URI    string
+	QRCode []byte
+}
+
+// TOTP generates and validates the codes of authenticator apps
+type TOTP struct {
+	issuer string
+}
+
+// NewTOTP creates a TOTP generator whose keys are shown under the issuer in the apps
+func NewTOTP(issuer string) *TOTP {
+	if issuer == "" {
+		issuer = "Licentia Usoris"
+	}
+
+	return &TOTP{
+		issuer: issuer,
+	}
+}
+
+// Generate generates a secret for the account and its otpauth:// URI and QR code PNG
+func (t *TOTP) Generate(accountName string) (*TOTPKey, error) {
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      t.issuer,
+		AccountName: accountName,
+		Period:      totpPeriod,
+		Digits:      otp.DigitsSix,
+		Algorithm:   otp.AlgorithmSHA1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := key.Image(qrCodeSize, qrCodeSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var qrCode bytes.Buffer
+	if err := png.Encode(&qrCode, img); err != nil {
+		return nil, err
+	}
+
+	return &TOTPKey{
+		Secret: key.Secret(),
+		URI:    key.URL(),
+		QRCode: qrCode.Bytes(),
+	}, nil
+}
+
+// Validate checks the code against the secret at the given time and returns the
+// time step it belongs to, so the caller can refuse a code that was already used
+func (t *TOTP) Validate(code, secret string, now time.Time) (int64, bool) {
+	current := now.Unix() / totpPeriod
+
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		valid, err := hotp.ValidateCustom(code, uint64(step), secret, hotp.ValidateOpts{
+			Digits:    otp.DigitsSix,
+			Algorithm: otp.AlgorithmSHA1,
+		})
+		if err == nil && valid {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
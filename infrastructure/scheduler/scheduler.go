@@ -0,0 +1,170 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/edutav/licentia-usoris/infrastructure/logger"
+	"github.com/edutav/licentia-usoris/infrastructure/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+// Returned when another replica holds the lock of the job
+var errLockHeld = errors.New("job lock held by another replica")
+
+// Job is a maintenance task run on every interval
+type Job struct {
+	Name     string
+	Interval time.Duration
+
+	// Run does the work and returns how many rows it affected
+	Run func(ctx context.Context) (int64, error)
+}
+
+// Scheduler runs the registered jobs on their intervals. Each run holds a Postgres
+// advisory lock keyed by the job name, so only one replica runs a job at a time.
+type Scheduler struct {
+	db   *sql.DB
+	jobs []Job
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewScheduler creates a new scheduler
+func NewScheduler(db *sql.DB) *Scheduler {
+	return &Scheduler{db: db}
+}
+
+// Register adds a job. A job without an interval is disabled.
+// Jobs have to be registered before the scheduler is started.
+func (s *Scheduler) Register(job Job) {
+	if job.Interval <= 0 {
+		logger.Base().WithField("job", job.Name).Info("Job disabled")
+		return
+	}
+
+	s.jobs = append(s.jobs, job)
+}
+
+// Start runs the registered jobs until the context is done or the scheduler is stopped
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go func(job Job) {
+			defer s.wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+
+	logger.Base().WithField("jobs", len(s.jobs)).Info("Scheduler started")
+}
+
+// Stop cancels the running jobs and waits for them to return, or for the context to be done
+func (s *Scheduler) Stop(ctx context.Context) error {
+	if s.cancel == nil {
+		return nil
+	}
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		logger.Base().Info("Scheduler stopped")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("stopping scheduler: %w", ctx.Err())
+	}
+}
+
+// loop runs the job on every interval until the context is done
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.run(ctx, job)
+		}
+	}
+}
+
+// run runs the job once when no other replica is running it, and records the run in the logs and metrics
+func (s *Scheduler) run(ctx context.Context, job Job) {
+	start := time.Now()
+
+	var affected int64
+	err := s.withLock(ctx, job.Name, func() error {
+		var err error
+		affected, err = job.Run(ctx)
+		return err
+	})
+	duration := time.Since(start)
+
+	entry := logger.Base().WithFields(logrus.Fields{
+		"job":         job.Name,
+		"duration_ms": duration.Milliseconds(),
+	})
+	switch {
+	case errors.Is(err, errLockHeld):
+		metrics.JobRuns.WithLabelValues(job.Name, "skipped").Inc()
+		return
+	case err != nil:
+		entry.WithError(err).Error("Error running job")
+	default:
+		entry.WithField("affected", affected).Info("Job finished")
+	}
+
+	metrics.JobRuns.WithLabelValues(job.Name, metrics.Result(err)).Inc()
+	metrics.JobDuration.WithLabelValues(job.Name).Observe(duration.Seconds())
+}
+
+// withLock runs fn holding the advisory lock of the job on a dedicated connection
+func (s *Scheduler) withLock(ctx context.Context, name string, fn func() error) error {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	key := lockKey(name)
+
+	var acquired bool
+	err = conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&acquired)
+	if err != nil {
+		return fmt.Errorf("acquiring job lock: %w", err)
+	}
+	if !acquired {
+		return errLockHeld
+	}
+	defer func() {
+		_, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, key)
+		if err != nil {
+			logger.Base().WithError(err).WithField("job", name).Error("Error releasing job lock")
+		}
+	}()
+
+	return fn()
+}
+
+// lockKey derives the advisory lock key of a job from its name
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("scheduler:" + name))
+	return int64(h.Sum64())
+}
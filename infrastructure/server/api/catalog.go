@@ -0,0 +1,159 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/edutav/licentia-usoris/internal/utils"
+)
+
+// ErrorDefinition is how an error is sent to the client. The code is stable and
+// machine-readable, clients should match it instead of the title or the detail.
+type ErrorDefinition struct {
+	Err    error
+	Code   string
+	Status int
+	Title  string
+	Detail string
+
+	// Request field the error is about, for the validation errors
+	Field string
+}
+
+// problem builds the problem of the definition for the request
+func (d ErrorDefinition) problem(r *http.Request) Problem {
+	return Problem{
+		Type:     problemTypePrefix + d.Code,
+		Title:    d.Title,
+		Status:   d.Status,
+		Detail:   d.Detail,
+		Instance: r.URL.Path,
+		Code:     d.Code,
+	}
+}
+
+// Sent for the errors missing from the catalog, without their message
+var internalError = ErrorDefinition{
+	Code:   "internal_error",
+	Status: http.StatusInternalServerError,
+	Title:  "Internal server error",
+	Detail: "An unexpected error occurred, please try again later",
+}
+
+// Sent for the validation errors, listing the invalid fields
+var validationFailed = ErrorDefinition{
+	Code:   "validation_failed",
+	Status: http.StatusBadRequest,
+	Title:  "Invalid request",
+	Detail: "One or more fields are invalid",
+}
+
+// Catalog of the errors sent to the clients
+var catalog = []ErrorDefinition{
+	// request errors
+	{Err: utils.ErrInvalidContentType, Code: "invalid_content_type", Status: http.StatusUnsupportedMediaType, Title: "Invalid content type", Detail: "Content type must be application/json"},
+	{Err: utils.ErrInvalidRequestBody, Code: "invalid_request_body", Status: http.StatusBadRequest, Title: "Invalid request body", Detail: "Request body must be a valid JSON object"},
+	{Err: utils.ErrInvalidBoolean, Code: "invalid_boolean", Status: http.StatusBadRequest, Title: "Invalid boolean", Detail: "Value must be true or false", Field: "value"},
+	{Err: utils.ErrInvalidUUID, Code: "invalid_uuid", Status: http.StatusBadRequest, Title: "Invalid UUID", Detail: "Please provide a valid UUID", Field: "uuid"},
+	{Err: utils.ErrRouteNotFound, Code: "route_not_found", Status: http.StatusNotFound, Title: "Route not found", Detail: "No route matches the requested path"},
+	{Err: utils.ErrMethodNotAllowed, Code: "method_not_allowed", Status: http.StatusMethodNotAllowed, Title: "Method not allowed", Detail: "The route does not accept the request method"},
+
+	// authentication and authorization errors
+	{Err: utils.ErrAuthenticationNeeded, Code: "authentication_required", Status: http.StatusUnauthorized, Title: "Unauthorized", Detail: "Authentication required"},
+	{Err: utils.ErrMissingBearerToken, Code: "missing_bearer_token", Status: http.StatusUnauthorized, Title: "Missing bearer token", Detail: "Please provide a bearer token"},
+	{Err: utils.ErrTokenExpired, Code: "token_expired", Status: http.StatusUnauthorized, Title: "Token expired", Detail: "Token has expired"},
+	{Err: utils.ErrInvalidToken, Code: "invalid_token", Status: http.StatusUnauthorized, Title: "Invalid token", Detail: "Invalid token"},
+	{Err: utils.ErrTokenRevoked, Code: "token_revoked", Status: http.StatusUnauthorized, Title: "Token revoked", Detail: "Token has been revoked"},
+	{Err: utils.ErrPermissionDenied, Code: "permission_denied", Status: http.StatusForbidden, Title: "Forbidden", Detail: "You do not have permission to perform this action"},
+
+	// login and session errors
+	{Err: utils.ErrInvalidCredentials, Code: "invalid_credentials", Status: http.StatusUnauthorized, Title: "Invalid credentials", Detail: "Invalid email or password"},
+	{Err: utils.ErrEmailNotVerified, Code: "email_not_verified", Status: http.StatusForbidden, Title: "Email not verified", Detail: "Please verify your email before logging in"},
+	{Err: utils.ErrUserBlocked, Code: "user_blocked", Status: http.StatusForbidden, Title: "User blocked", Detail: "User is blocked"},
+	{Err: utils.ErrUserDeleted, Code: "user_deleted", Status: http.StatusForbidden, Title: "User deleted", Detail: "User is deleted"},
+	{Err: utils.ErrMissingRefreshToken, Code: "missing_refresh_token", Status: http.StatusBadRequest, Title: "Missing refresh token", Detail: "Please provide a refresh token", Field: "refresh_token"},
+	{Err: utils.ErrInvalidRefreshToken, Code: "invalid_refresh_token", Status: http.StatusUnauthorized, Title: "Invalid refresh token", Detail: "Invalid refresh token"},
+	{Err: utils.ErrRefreshTokenReused, Code: "refresh_token_reused", Status: http.StatusUnauthorized, Title: "Refresh token reused", Detail: "Refresh token reused, please log in again"},
+
+	// user field errors
+	{Err: utils.ErrInvalidName, Code: "missing_name", Status: http.StatusBadRequest, Title: "Missing name", Detail: "Please provide a name", Field: "name"},
+	{Err: utils.ErrUserNameTooShort, Code: "name_too_short", Status: http.StatusBadRequest, Title: "Name too short", Detail: fmt.Sprintf("Name must be at least %d characters", utils.UserNameMinLength), Field: "name"},
+	{Err: utils.ErrUserNameTooLong, Code: "name_too_long", Status: http.StatusBadRequest, Title: "Name too long", Detail: fmt.Sprintf("Name must be at most %d characters", utils.UserNameMaxLength), Field: "name"},
+	{Err: utils.ErrUserNameWithNumericVals, Code: "name_with_digits", Status: http.StatusBadRequest, Title: "Name with digits", Detail: "Name must not contain digits", Field: "name"},
+	{Err: utils.ErrMissingEmail, Code: "missing_email", Status: http.StatusBadRequest, Title: "Missing email", Detail: "Please provide an email address", Field: "email"},
+	{Err: utils.ErrInvalidEmail, Code: "invalid_email", Status: http.StatusBadRequest, Title: "Invalid email", Detail: "Please provide a valid email address", Field: "email"},
+	{Err: utils.ErrMissingPhoneNumber, Code: "missing_phone_number", Status: http.StatusBadRequest, Title: "Missing phone number", Detail: "Please provide a phone number", Field: "phone_number"},
+	{Err: utils.ErrInvalidPhoneNumber, Code: "invalid_phone_number", Status: http.StatusBadRequest, Title: "Invalid phone number", Detail: "Phone number must have 11 digits", Field: "phone_number"},
+	{Err: utils.ErrDOBFormat, Code: "invalid_date_of_birth", Status: http.StatusBadRequest, Title: "Invalid date of birth", Detail: "Date of birth must be in the YYYY-MM-DD format", Field: "date_of_birth"},
+	{Err: utils.ErrPasswordInvalid, Code: "missing_password", Status: http.StatusBadRequest, Title: "Missing password", Detail: "Please provide a password", Field: "password"},
+	{Err: utils.ErrPasswordTooShort, Code: "password_too_short", Status: http.StatusBadRequest, Title: "Password too short", Detail: fmt.Sprintf("Password must be at least %d characters", utils.PasswordMinLength), Field: "password"},
+	{Err: utils.ErrPasswordTooLong, Code: "password_too_long", Status: http.StatusBadRequest, Title: "Password too long", Detail: fmt.Sprintf("Password must be at most %d characters", utils.PasswordMaxLength), Field: "password"},
+	{Err: utils.ErrPasswordSecurity, Code: "password_not_safe", Status: http.StatusBadRequest, Title: "Password not safe", Detail: "Password must contain at least one uppercase letter, one lowercase letter, one number, and one special character", Field: "password"},
+	{Err: utils.ErrPasswordReused, Code: "password_reused", Status: http.StatusBadRequest, Title: "Password reused", Detail: "Password must differ from the current and recent passwords", Field: "password"},
+
+	// registration errors
+	{Err: utils.ErrDuplicateEmail, Code: "email_taken", Status: http.StatusConflict, Title: "Email already exists", Detail: "Email already exists"},
+	{Err: utils.ErrPreRegistredUserNotFound, Code: "pre_registration_not_found", Status: http.StatusNotFound, Title: "Pre-registration not found", Detail: "Pre-registration not found, please pre-register first"},
+	{Err: utils.ErrMissingOTP, Code: "missing_otp", Status: http.StatusBadRequest, Title: "Missing OTP code", Detail: "Please provide the OTP code", Field: "otp"},
+	{Err: utils.ErrOtpLength, Code: "invalid_otp_length", Status: http.StatusBadRequest, Title: "Invalid OTP code", Detail: "OTP code must have 6 digits", Field: "otp"},
+	{Err: utils.ErrOtpNums, Code: "invalid_otp_format", Status: http.StatusBadRequest, Title: "Invalid OTP code", Detail: "OTP code must only contain digits", Field: "otp"},
+	{Err: utils.ErrInvalidOTP, Code: "invalid_otp", Status: http.StatusBadRequest, Title: "Invalid OTP code", Detail: "Invalid OTP code"},
+	{Err: utils.ErrOTPExpired, Code: "otp_expired", Status: http.StatusGone, Title: "OTP code expired", Detail: "OTP code expired, please request a new one"},
+	{Err: utils.ErrOTPAlreadyVerified, Code: "email_already_verified", Status: http.StatusConflict, Title: "Email already verified", Detail: "Email already verified"},
+	{Err: utils.ErrOTPLocked, Code: "otp_locked", Status: http.StatusTooManyRequests, Title: "Too many attempts", Detail: "Too many invalid attempts, request a new OTP code"},
+	{Err: utils.ErrOTPCooldown, Code: "otp_cooldown", Status: http.StatusTooManyRequests, Title: "Too many requests", Detail: "OTP code requested too recently, please wait before requesting a new one"},
+	{Err: utils.ErrOTPDailyLimit, Code: "otp_daily_limit", Status: http.StatusTooManyRequests, Title: "Too many requests", Detail: "Too many OTP codes requested today, please try again tomorrow"},
+	{Err: utils.ErrSMTPServerIssue, Code: "email_delivery_failed", Status: http.StatusInternalServerError, Title: "Email delivery failed", Detail: "The email could not be sent, please try again later"},
+
+	// password errors
+	{Err: utils.ErrInvalidPasswordResetToken, Code: "invalid_password_reset_token", Status: http.StatusBadRequest, Title: "Invalid token", Detail: "Password reset token is invalid or expired"},
+	{Err: utils.ErrIncorrectPassword, Code: "incorrect_password", Status: http.StatusForbidden, Title: "Incorrect password", Detail: "Current password is incorrect"},
+
+	// mfa errors
+	{Err: utils.ErrInvalidMFACode, Code: "invalid_mfa_code", Status: http.StatusBadRequest, Title: "Invalid code", Detail: "Code is invalid or was already used"},
+	{Err: utils.ErrInvalidMFAChallenge, Code: "invalid_mfa_challenge", Status: http.StatusUnauthorized, Title: "Invalid challenge", Detail: "Two-factor authentication challenge is invalid or expired, please log in again"},
+	{Err: utils.ErrMFANotEnrolled, Code: "mfa_not_enrolled", Status: http.StatusNotFound, Title: "Not enrolled", Detail: "Start the enrollment of an authenticator app first"},
+	{Err: utils.ErrMFAAlreadyEnabled, Code: "mfa_already_enabled", Status: http.StatusConflict, Title: "Already enabled", Detail: "Two-factor authentication is already enabled"},
+	{Err: utils.ErrMFANotEnabled, Code: "mfa_not_enabled", Status: http.StatusConflict, Title: "Not enabled", Detail: "Two-factor authentication is not enabled"},
+
+	// user administration errors
+	{Err: utils.ErrUserNotFound, Code: "user_not_found", Status: http.StatusNotFound, Title: "User not found", Detail: "User not found"},
+	{Err: utils.ErrUserAlreadyBlocked, Code: "user_already_blocked", Status: http.StatusConflict, Title: "User already blocked", Detail: "User already blocked"},
+	{Err: utils.ErrUserNotBlocked, Code: "user_not_blocked", Status: http.StatusConflict, Title: "User is not blocked", Detail: "User is not blocked"},
+	{Err: utils.ErrUserAlreadyDeleted, Code: "user_already_deleted", Status: http.StatusConflict, Title: "User already deleted", Detail: "User already deleted"},
+	{Err: utils.ErrUserNotDeleted, Code: "user_not_deleted", Status: http.StatusConflict, Title: "User is not deleted", Detail: "User is not deleted"},
+	{Err: utils.ErrUserExceedsCaller, Code: "user_exceeds_caller", Status: http.StatusForbidden, Title: "Forbidden", Detail: "The user holds permissions you do not hold"},
+	{Err: utils.ErrMissingBlockReason, Code: "missing_block_reason", Status: http.StatusBadRequest, Title: "Missing block reason", Detail: "Please provide the reason for blocking the user", Field: "reason"},
+	{Err: utils.ErrBlockReasonTooLong, Code: "block_reason_too_long", Status: http.StatusBadRequest, Title: "Block reason too long", Detail: fmt.Sprintf("Block reason must be at most %d characters", utils.BlockReasonMaxLength), Field: "reason"},
+	{Err: utils.ErrInvalidSortField, Code: "invalid_sort_field", Status: http.StatusBadRequest, Title: "Invalid sort field", Detail: "Sort must be one of name, email, created_at, updated_at or last_login", Field: "sort"},
+	{Err: utils.ErrInvalidSortOrder, Code: "invalid_sort_order", Status: http.StatusBadRequest, Title: "Invalid sort order", Detail: "Order must be asc or desc", Field: "order"},
+	{Err: utils.ErrPageOutOfRange, Code: "page_out_of_range", Status: http.StatusBadRequest, Title: "Page out of range", Detail: "Page must not skip more than 10000 users, narrow the filters instead", Field: "page"},
+
+	// role, permission and group errors
+	{Err: utils.ErrInvalidRoleName, Code: "invalid_role_name", Status: http.StatusBadRequest, Title: "Invalid role name", Detail: "Role name must have 2 to 50 lowercase letters, digits, \"-\" or \"_\"", Field: "name"},
+	{Err: utils.ErrRoleNotFound, Code: "role_not_found", Status: http.StatusNotFound, Title: "Role not found", Detail: "Role not found"},
+	{Err: utils.ErrDuplicateRole, Code: "role_exists", Status: http.StatusConflict, Title: "Role already exists", Detail: "Role already exists"},
+	{Err: utils.ErrRoleExceedsGrantor, Code: "role_exceeds_grantor", Status: http.StatusForbidden, Title: "Forbidden", Detail: "The change grants permissions you do not hold"},
+	{Err: utils.ErrRoleNotAssigned, Code: "role_not_assigned", Status: http.StatusNotFound, Title: "Role not assigned", Detail: "Role not assigned to user"},
+	{Err: utils.ErrInvalidPermissionName, Code: "invalid_permission_name", Status: http.StatusBadRequest, Title: "Invalid permission name", Detail: "Permission name must follow the \"verb:resource\" format", Field: "name"},
+	{Err: utils.ErrPermissionNotFound, Code: "permission_not_found", Status: http.StatusNotFound, Title: "Permission not found", Detail: "Permission not found"},
+	{Err: utils.ErrDuplicatePermission, Code: "permission_exists", Status: http.StatusConflict, Title: "Permission already exists", Detail: "Permission already exists"},
+	{Err: utils.ErrPermissionNotAttached, Code: "permission_not_attached", Status: http.StatusNotFound, Title: "Permission not attached", Detail: "Permission not attached to role"},
+	{Err: utils.ErrInvalidGroupName, Code: "invalid_group_name", Status: http.StatusBadRequest, Title: "Invalid group name", Detail: "Group name must have 2 to 50 lowercase letters, digits, \"-\" or \"_\"", Field: "name"},
+	{Err: utils.ErrGroupNotFound, Code: "group_not_found", Status: http.StatusNotFound, Title: "Group not found", Detail: "Group not found"},
+	{Err: utils.ErrDuplicateGroup, Code: "group_exists", Status: http.StatusConflict, Title: "Group already exists", Detail: "Group already exists"},
+	{Err: utils.ErrGroupMemberNotFound, Code: "group_member_not_found", Status: http.StatusNotFound, Title: "Member not found", Detail: "User is not a member of the group"},
+	{Err: utils.ErrGroupRoleNotAssigned, Code: "group_role_not_assigned", Status: http.StatusNotFound, Title: "Role not assigned", Detail: "Role not assigned to group"},
+}
+
+// lookupError finds the definition of the error, the internal error when it is not in the catalog
+func lookupError(err error) ErrorDefinition {
+	for _, definition := range catalog {
+		if errors.Is(err, definition.Err) {
+			return definition
+		}
+	}
+
+	return internalError
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/edutav/licentia-usoris/infrastructure/logger"
+)
+
+// Media type of the error responses
+const ProblemContentType = "application/problem+json"
+
+// Prefix of the problem types, followed by the code of the error
+const problemTypePrefix = "urn:licentia-usoris:problem:"
+
+// Problem is an RFC 7807 problem details response
+type Problem struct {
+	Type     string       `json:"type" example:"urn:licentia-usoris:problem:validation_failed"`
+	Title    string       `json:"title" example:"Invalid request"`
+	Status   int          `json:"status" example:"400"`
+	Detail   string       `json:"detail,omitempty" example:"One or more fields are invalid"`
+	Instance string       `json:"instance,omitempty" example:"/api/v1/user/pre-register"`
+	Code     string       `json:"code" example:"validation_failed"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError is an invalid field of the request
+type FieldError struct {
+	Field   string `json:"field" example:"email"`
+	Code    string `json:"code" example:"invalid_email"`
+	Message string `json:"message" example:"Please provide a valid email address"`
+}
+
+// fieldError names the request field a validation error is about
+type fieldError struct {
+	field string
+	err   error
+}
+
+func (e *fieldError) Error() string {
+	return e.field + ": " + e.err.Error()
+}
+
+func (e *fieldError) Unwrap() error {
+	return e.err
+}
+
+// WithField reports a validation error on the given field, in place of the field of its catalog entry.
+// Errors that are not about a field are sent unchanged.
+func WithField(err error, field string) error {
+	if err == nil {
+		return nil
+	}
+	return &fieldError{field: field, err: err}
+}
+
+// SendError sends the problem of the error from the catalog. Validation errors, and errors
+// joined with errors.Join, are sent as a validation problem listing every invalid field.
+// Errors missing from the catalog are logged and sent as an internal error.
+func SendError(w http.ResponseWriter, r *http.Request, err error) {
+	problem := newProblem(r, err)
+	if problem.Status >= http.StatusInternalServerError {
+		logger.FromContext(r.Context()).WithError(err).Error("Error handling request")
+	}
+
+	sendProblem(w, problem)
+}
+
+// newProblem builds the problem of the error
+func newProblem(r *http.Request, err error) Problem {
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+
+	// The validation problem is only sent when every error is about a field
+	fields := make([]FieldError, 0, len(errs))
+	for _, err := range errs {
+		definition := lookupError(err)
+		if definition.Field == "" {
+			return definition.problem(r)
+		}
+
+		field := definition.Field
+		var withField *fieldError
+		if errors.As(err, &withField) {
+			field = withField.field
+		}
+
+		fields = append(fields, FieldError{
+			Field:   field,
+			Code:    definition.Code,
+			Message: definition.Detail,
+		})
+	}
+
+	problem := validationFailed.problem(r)
+	problem.Errors = fields
+	return problem
+}
+
+// sendProblem writes the problem as application/problem+json
+func sendProblem(w http.ResponseWriter, problem Problem) {
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
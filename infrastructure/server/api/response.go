@@ -5,15 +5,9 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
-// Error response is the response object for the API
-type ErrorResponse struct {
-	Status  int    `json:"status"`
-	Message string `json:"message,omitempty"`
-	Error   string `json:"error,omitempty"`
-}
-
 // Single response object for the API
 type SingleResponse struct {
 	Status  int         `json:"status"`
@@ -46,24 +40,15 @@ type Links struct {
 	Last     string `json:"last"`
 }
 
+// Largest page size a client may request
+const maxPageSize = 100
+
 // PaginationParams holds the parameters for pagination
 type PaginationParams struct {
 	Page     int
 	PageSize int
 }
 
-// SendErrorResponse sends an error response
-func SendErrorResponse(w http.ResponseWriter, status int, message string, err string) {
-	response := ErrorResponse{
-		Status:  status,
-		Message: message,
-		Error:   err,
-	}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(response)
-}
-
 // SendSingleResponse sends a single object response
 func SendSingleResponse(w http.ResponseWriter, status int, message string, data interface{}) {
 	response := SingleResponse{
@@ -102,16 +87,27 @@ func GetPaginationParams(r *http.Request) PaginationParams {
 		pageSize = 10
 	}
 
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
 	return PaginationParams{
 		Page:     page,
 		PageSize: pageSize,
 	}
 }
 
-// BuildPagination constructs the pagination metadata and links
+// BuildPagination constructs the pagination metadata and links.
+// The baseURL may already carry a query string, such as the filters of the request.
 func BuildPagination(totalItems, page, pageSize int, baseURL string) (Meta, Links) {
 	totalPages := (totalItems + pageSize - 1) / pageSize
 
+	separator := "?"
+	if strings.Contains(baseURL, "?") {
+		separator = "&"
+	}
+	baseURL += separator
+
 	meta := Meta{
 		TotalItems:   totalItems,
 		TotalPages:   totalPages,
@@ -120,17 +116,17 @@ func BuildPagination(totalItems, page, pageSize int, baseURL string) (Meta, Link
 	}
 
 	links := Links{
-		Self:  fmt.Sprintf("%s?page=%d&limit=%d", baseURL, page, pageSize),
-		First: fmt.Sprintf("%s?page=1&limit=%d", baseURL, pageSize),
-		Last:  fmt.Sprintf("%s?page=%d&limit=%d", baseURL, totalPages, pageSize),
+		Self:  fmt.Sprintf("%spage=%d&limit=%d", baseURL, page, pageSize),
+		First: fmt.Sprintf("%spage=1&limit=%d", baseURL, pageSize),
+		Last:  fmt.Sprintf("%spage=%d&limit=%d", baseURL, totalPages, pageSize),
 	}
 
 	if page > 1 {
-		links.Previous = fmt.Sprintf("%s?page=%d&limit=%d", baseURL, page-1, pageSize)
+		links.Previous = fmt.Sprintf("%spage=%d&limit=%d", baseURL, page-1, pageSize)
 	}
 
 	if page < totalPages {
-		links.Next = fmt.Sprintf("%s?page=%d&limit=%d", baseURL, page+1, pageSize)
+		links.Next = fmt.Sprintf("%spage=%d&limit=%d", baseURL, page+1, pageSize)
 	}
 
 	return meta, links
@@ -2,35 +2,130 @@ package server
 
 import (
 	"database/sql"
-	"log"
 	"net/http"
 
+	"github.com/edutav/licentia-usoris/infrastructure/auth"
 	"github.com/edutav/licentia-usoris/infrastructure/email"
+	"github.com/edutav/licentia-usoris/infrastructure/health"
+	"github.com/edutav/licentia-usoris/infrastructure/logger"
+	otpapp "github.com/edutav/licentia-usoris/infrastructure/otp_app"
 	"github.com/edutav/licentia-usoris/internal/config"
 	"github.com/edutav/licentia-usoris/internal/domain/reporitory/postgres"
 	"github.com/edutav/licentia-usoris/internal/presentation/handlers"
+	"github.com/edutav/licentia-usoris/internal/presentation/middleware"
 	"github.com/edutav/licentia-usoris/internal/presentation/routes"
 	"github.com/edutav/licentia-usoris/internal/usecases"
-	"github.com/edutav/licentia-usoris/internal/usecases/validator"
 )
 
 type Server struct {
 	router http.Handler
 }
 
-func NewServer(db *sql.DB, emailSender *email.Sender, cfg *config.Config) *Server {
-	log.Println("Initializing components for server")
+func NewServer(
+	db *sql.DB,
+	emailSender *email.Sender,
+	tokenService auth.TokenService,
+	blacklist auth.TokenBlacklist,
+	secretCipher *auth.SecretCipher,
+	readiness *health.Readiness,
+	cfg *config.Config,
+) *Server {
+	logger.Base().Info("Initializing components for server")
 
 	indexHandler := handlers.NewIndexHandler()
+	healthHandler := handlers.NewHealthHandler(readiness)
 
 	// Components the users
 	userRepository := postgres.NewUserRepository(db)
-	userUseCase := usecases.NewUserUseCase(userRepository, emailSender, validator.ValidateUserPassword)
+	refreshTokenRepository := postgres.NewRefreshTokenRepository(db)
+	mfaRepository := postgres.NewMFARepository(db)
+	verificationCodeUseCase := usecases.NewVerificationCodeUseCase(
+		postgres.NewVerificationCodeRepository(db),
+		cfg.Verification.Secret,
+		cfg.Verification.CodeTTL,
+		cfg.Verification.MaxAttempts,
+		cfg.Verification.ResendCooldown,
+		cfg.Verification.DailyLimit,
+	)
+	userUseCase := usecases.NewUserUseCase(
+		userRepository,
+		refreshTokenRepository,
+		mfaRepository,
+		verificationCodeUseCase,
+		emailSender,
+		tokenService,
+		blacklist,
+		cfg.JWT.RefreshTokenTTL,
+		cfg.MFA.ChallengeTTL,
+		nil, // the default password policy
+	)
 	userHandler := handlers.NewUserHandler(userUseCase)
 
+	// Components the two-factor authentication
+	mfaUseCase := usecases.NewMFAUseCase(
+		userRepository,
+		mfaRepository,
+		refreshTokenRepository,
+		otpapp.NewTOTP(cfg.MFA.Issuer),
+		secretCipher,
+		tokenService,
+		blacklist,
+		cfg.JWT.RefreshTokenTTL,
+	)
+	mfaHandler := handlers.NewMFAHandler(mfaUseCase)
+
+	// Components the password recovery
+	passwordResetRepository := postgres.NewPasswordResetRepository(db)
+	passwordUseCase := usecases.NewPasswordUseCase(
+		userRepository,
+		passwordResetRepository,
+		refreshTokenRepository,
+		emailSender,
+		tokenService,
+		blacklist,
+		cfg.JWT.RefreshTokenTTL,
+		cfg.PasswordReset.TokenTTL,
+		cfg.PasswordReset.URL,
+		nil, // the default password policy
+	)
+	passwordHandler := handlers.NewPasswordHandler(passwordUseCase)
+
+	// Components the administration of users
+	userAdminUseCase := usecases.NewUserAdminUseCase(userRepository, refreshTokenRepository, tokenService, blacklist)
+	userAdminHandler := handlers.NewUserAdminHandler(userAdminUseCase)
+
+	// Components the roles and permissions
+	roleRepository := postgres.NewRoleRepository(db)
+	permissionRepository := postgres.NewPermissionRepository(db)
+	roleUseCase := usecases.NewRoleUseCase(roleRepository, permissionRepository, userRepository)
+	permissionUseCase := usecases.NewPermissionUseCase(permissionRepository)
+	roleHandler := handlers.NewRoleHandler(roleUseCase)
+	permissionHandler := handlers.NewPermissionHandler(permissionUseCase)
+
+	// Components the groups
+	groupRepository := postgres.NewGroupRepository(db)
+	groupUseCase := usecases.NewGroupUseCase(groupRepository, roleRepository, userRepository)
+	groupHandler := handlers.NewGroupHandler(groupUseCase)
+
+	// Components the authentication and authorization
+	authenticate := middleware.Authenticate(tokenService, blacklist)
+	authorization := middleware.NewAuthorization(auth.NewAuthorizer(userRepository))
+
 	// Create router
-	router := routes.NewRouter(indexHandler, userHandler)
-	log.Println("Router created")
+	router := routes.NewRouter(
+		indexHandler,
+		healthHandler,
+		userHandler,
+		passwordHandler,
+		mfaHandler,
+		roleHandler,
+		permissionHandler,
+		groupHandler,
+		userAdminHandler,
+		authenticate,
+		authorization,
+	)
+	logger.Base().Info("Router created")
 
 	return &Server{
 		router: router,
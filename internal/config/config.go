@@ -2,30 +2,49 @@ package config
 
 import (
 	"fmt"
-	"log"
 	"os"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	SMTP     SMTPConfig
-	JWT      JWTConfig
-	Env      Environment
+	Server        ServerConfig
+	Database      DatabaseConfig
+	SMTP          SMTPConfig
+	JWT           JWTConfig
+	PasswordReset PasswordResetConfig `mapstructure:"password_reset"`
+	MFA           MFAConfig
+	Verification  VerificationConfig
+	Maintenance   MaintenanceConfig
+	Health        HealthConfig
+	Tracing       TracingConfig
+	Log           LogConfig
+	Env           Environment
 }
 
 type ServerConfig struct {
-	Port string
+	Port              string
+	ReadTimeout       time.Duration `mapstructure:"read_timeout"`
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout"`
+	WriteTimeout      time.Duration `mapstructure:"write_timeout"`
+	IdleTimeout       time.Duration `mapstructure:"idle_timeout"`
+	MaxHeaderBytes    int           `mapstructure:"max_header_bytes"`
+
+	// Time the service stays up but unready on shutdown, so traffic stops being routed to it
+	ShutdownDelay time.Duration `mapstructure:"shutdown_delay"`
+
+	// Time the in-flight requests, jobs and emails have to finish on shutdown
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
 }
 
 type DatabaseConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	Name     string
+	Host        string
+	Port        string
+	User        string
+	Password    string
+	Name        string
+	AutoMigrate bool `mapstructure:"auto_migrate"`
 }
 
 type SMTPConfig struct {
@@ -36,17 +55,82 @@ type SMTPConfig struct {
 }
 
 type JWTConfig struct {
-	SecretKey string
+	SecretKey       string        `mapstructure:"secret"`
+	Issuer          string        `mapstructure:"issuer"`
+	Audience        []string      `mapstructure:"audience"`
+	AccessTokenTTL  time.Duration `mapstructure:"access_token_ttl"`
+	RefreshTokenTTL time.Duration `mapstructure:"refresh_token_ttl"`
+}
+
+type PasswordResetConfig struct {
+	TokenTTL time.Duration `mapstructure:"token_ttl"`
+	URL      string        `mapstructure:"url"`
+}
+
+type MFAConfig struct {
+	Issuer        string        `mapstructure:"issuer"`
+	EncryptionKey string        `mapstructure:"encryption_key"`
+	ChallengeTTL  time.Duration `mapstructure:"challenge_ttl"`
+}
+
+type VerificationConfig struct {
+	Secret         string        `mapstructure:"secret"`
+	CodeTTL        time.Duration `mapstructure:"code_ttl"`
+	MaxAttempts    int           `mapstructure:"max_attempts"`
+	ResendCooldown time.Duration `mapstructure:"resend_cooldown"`
+	DailyLimit     int           `mapstructure:"daily_limit"`
+}
+
+// Intervals of the maintenance jobs, a job is disabled without one
+type MaintenanceConfig struct {
+	BlacklistPurgeInterval        time.Duration `mapstructure:"blacklist_purge_interval"`
+	PreRegistrationPurgeInterval  time.Duration `mapstructure:"pre_registration_purge_interval"`
+	VerificationCodePurgeInterval time.Duration `mapstructure:"verification_code_purge_interval"`
+}
+
+type HealthConfig struct {
+	// Time each readiness check has to finish
+	CheckTimeout time.Duration `mapstructure:"check_timeout"`
+
+	// Dial the SMTP server in the readiness checks
+	CheckSMTP bool `mapstructure:"check_smtp"`
+}
+
+type TracingConfig struct {
+	ServiceName string `mapstructure:"service_name"`
+
+	// Exporter of the spans: none, stdout, file or otlp
+	Exporter string `mapstructure:"exporter"`
+
+	// File the spans are appended to with the file exporter
+	File string `mapstructure:"file"`
+
+	// Host and port of the OTLP/HTTP collector with the otlp exporter
+	Endpoint string `mapstructure:"endpoint"`
+	Insecure bool   `mapstructure:"insecure"`
+
+	// Ratio of the traces started here that are sampled
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
+
+type LogConfig struct {
+	// Lowest level logged: trace, debug, info, warn, error, fatal or panic
+	Level string `mapstructure:"level"`
+
+	// Format of the logs: json, or text for local use
+	Format string `mapstructure:"format"`
 }
 
 type Environment struct {
 	Env string
+
+	// Config file the configuration was read from
+	File string `mapstructure:"-"`
 }
 
+// Load reads the config file of the APP_ENV environment. It does not log, as the
+// logger is only set up from the loaded configuration.
 func Load() (*Config, error) {
-	dir, _ := os.Getwd()
-	log.Println("Current directory: ", dir)
-
 	env := os.Getenv("APP_ENV")
 	if env == "" {
 		return nil, fmt.Errorf("APP_ENV not set")
@@ -60,20 +144,17 @@ func Load() (*Config, error) {
 
 	err := viper.ReadInConfig()
 	if err != nil {
-		log.Printf("Error reading config file, %s", err)
-		return nil, err
+		return nil, fmt.Errorf("reading config file: %w", err)
 	}
 
-	log.Printf("Using config: %s", viper.ConfigFileUsed())
-
 	var config Config
 	err = viper.Unmarshal(&config)
 	if err != nil {
-		log.Printf("Unable to decode into struct, %v", err)
-		return nil, err
+		return nil, fmt.Errorf("decoding config file %s: %w", viper.ConfigFileUsed(), err)
 	}
 
 	config.Env.Env = env
+	config.Env.File = viper.ConfigFileUsed()
 
 	return &config, nil
 }
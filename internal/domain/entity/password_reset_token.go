@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+type PasswordResetToken struct {
+	UUID      string
+	UserUUID  string
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UsedAt    time.Time
+}
+
+func (t *PasswordResetToken) IsUsed() bool {
+	return !t.UsedAt.IsZero()
+}
+
+func (t *PasswordResetToken) IsExpired() bool {
+	return t.ExpiresAt.Before(time.Now().UTC())
+}
@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+type Permission struct {
+	UUID        string
+	Name        string
+	Description string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
@@ -6,7 +6,6 @@ type PreRegistration struct {
 	UUID         string
 	Email        string
 	PasswordHash string
-	CodeOTP      string
 	UserData     *User
 	ExpiresAt    time.Time
 	IsVerified   bool
@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// RecoveryCode is a one-time code replacing a TOTP code when the authenticator app is lost
+type RecoveryCode struct {
+	UUID      string
+	UserUUID  string
+	CodeHash  string
+	CreatedAt time.Time
+	UsedAt    time.Time
+}
+
+func (c *RecoveryCode) IsUsed() bool {
+	return !c.UsedAt.IsZero()
+}
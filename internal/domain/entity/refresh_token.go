@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+// RefreshToken is an opaque refresh token, stored by the hash of its value.
+// Its family groups the tokens rotated from the same login.
+// It replaces the signed JWT refresh tokens, which are no longer issued nor accepted.
+type RefreshToken struct {
+	UUID       string
+	UserUUID   string
+	FamilyID   string
+	TokenHash  string
+	ExpiresAt  time.Time
+	CreatedAt  time.Time
+	RotatedAt  time.Time
+	RevokedAt  time.Time
+	ReplacedBy string
+}
+
+func (t *RefreshToken) IsRotated() bool {
+	return !t.RotatedAt.IsZero()
+}
+
+func (t *RefreshToken) IsRevoked() bool {
+	return !t.RevokedAt.IsZero()
+}
+
+func (t *RefreshToken) IsExpired() bool {
+	return t.ExpiresAt.Before(time.Now().UTC())
+}
@@ -20,6 +20,8 @@ type User struct {
 	DeletedAt       time.Time
 	IsDeleted       bool
 	LastLogin       time.Time
+	BlockedAt       time.Time
+	BlockedReason   string
 }
 
 func (u *User) CheckPassword(password string) bool {
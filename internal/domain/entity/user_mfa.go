@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// UserMFA is the authenticator app enrollment of a user.
+// The secret is stored encrypted and the enrollment is pending until EnabledAt is set.
+type UserMFA struct {
+	UserUUID        string
+	SecretEncrypted string
+	EnabledAt       time.Time
+	LastUsedStep    int64
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+func (m *UserMFA) IsEnabled() bool {
+	return !m.EnabledAt.IsZero()
+}
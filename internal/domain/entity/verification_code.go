@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+// VerificationCode is a code sent to a subject, such as an email, to prove it owns it.
+// Only the hash of the code is stored.
+type VerificationCode struct {
+	UUID       string
+	Purpose    string
+	Subject    string
+	CodeHash   string
+	Attempts   int
+	ExpiresAt  time.Time
+	CreatedAt  time.Time
+	ConsumedAt time.Time
+
+	// Times the code was sent since the start of the current day window
+	SendCount           int
+	SendWindowStartedAt time.Time
+}
+
+func (c *VerificationCode) IsConsumed() bool {
+	return !c.ConsumedAt.IsZero()
+}
+
+func (c *VerificationCode) IsExpired() bool {
+	return c.ExpiresAt.Before(time.Now().UTC())
+}
@@ -0,0 +1,42 @@
+package reporitory
+
+import (
+	"context"
+
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+)
+
+type GroupRepository interface {
+	// Create group
+	CreateGroup(ctx context.Context, group *entity.Group) error
+
+	// List groups
+	ListGroups(ctx context.Context) ([]*entity.Group, error)
+
+	// Get group by UUID
+	GetGroupByUUID(ctx context.Context, groupUUID string) (*entity.Group, error)
+
+	// Update group
+	UpdateGroup(ctx context.Context, group *entity.Group) error
+
+	// Delete group, its members and its roles
+	DeleteGroup(ctx context.Context, groupUUID string) error
+
+	// List the members of the group
+	ListGroupMembers(ctx context.Context, groupUUID string) ([]*entity.User, error)
+
+	// Add user to group
+	AddMember(ctx context.Context, groupUUID, userUUID string) error
+
+	// Remove user from group
+	RemoveMember(ctx context.Context, groupUUID, userUUID string) error
+
+	// List the roles assigned to the group
+	ListGroupRoles(ctx context.Context, groupUUID string) ([]*entity.Role, error)
+
+	// Assign role to group
+	AssignRole(ctx context.Context, groupUUID, roleUUID string) error
+
+	// Revoke role from group
+	RevokeRole(ctx context.Context, groupUUID, roleUUID string) error
+}
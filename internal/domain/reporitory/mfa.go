@@ -0,0 +1,37 @@
+package reporitory
+
+import (
+	"context"
+	"time"
+
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+)
+
+type MFARepository interface {
+	// Save a pending enrollment of the user, replacing a previous pending one
+	SavePendingMFA(ctx context.Context, mfa *entity.UserMFA) error
+
+	// Get the enrollment of the user
+	GetMFA(ctx context.Context, userUUID string) (*entity.UserMFA, error)
+
+	// Enable the pending enrollment of the user with the time step of its first code
+	EnableMFA(ctx context.Context, userUUID string, step int64, enabledAt time.Time) error
+
+	// Record the time step of a used code, failing when it is not newer than the last one
+	UseMFAStep(ctx context.Context, userUUID string, step int64) error
+
+	// Delete the enrollment of the user and its recovery codes
+	DeleteMFA(ctx context.Context, userUUID string) error
+
+	// Replace the recovery codes of the user with the given hashes
+	ReplaceRecoveryCodes(ctx context.Context, userUUID string, codeHashes []string) error
+
+	// List the recovery codes of the user that were not used
+	ListUnusedRecoveryCodes(ctx context.Context, userUUID string) ([]*entity.RecoveryCode, error)
+
+	// Mark the recovery code as used, failing when it was already used
+	UseRecoveryCode(ctx context.Context, codeUUID string) error
+
+	// Count the recovery codes of the user that were not used
+	CountUnusedRecoveryCodes(ctx context.Context, userUUID string) (int, error)
+}
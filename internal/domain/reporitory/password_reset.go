@@ -0,0 +1,19 @@
+package reporitory
+
+import (
+	"context"
+	"time"
+
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+)
+
+type PasswordResetRepository interface {
+	// Create password reset token, invalidating the unused tokens of the user
+	CreatePasswordResetToken(ctx context.Context, token *entity.PasswordResetToken) error
+
+	// Get password reset token by the hash of its value
+	GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error)
+
+	// Mark the token as used and set the new password of its user
+	ResetPassword(ctx context.Context, token *entity.PasswordResetToken, passwordHash string, updatedAt time.Time) error
+}
@@ -0,0 +1,24 @@
+package reporitory
+
+import (
+	"context"
+
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+)
+
+type PermissionRepository interface {
+	// Create permission
+	CreatePermission(ctx context.Context, permission *entity.Permission) error
+
+	// List permissions
+	ListPermissions(ctx context.Context) ([]*entity.Permission, error)
+
+	// Get permission by UUID
+	GetPermissionByUUID(ctx context.Context, permissionUUID string) (*entity.Permission, error)
+
+	// Update permission
+	UpdatePermission(ctx context.Context, permission *entity.Permission) error
+
+	// Delete permission and its grants
+	DeletePermission(ctx context.Context, permissionUUID string) error
+}
@@ -0,0 +1,342 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/edutav/licentia-usoris/infrastructure/logger"
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+	"github.com/edutav/licentia-usoris/internal/domain/reporitory"
+	"github.com/edutav/licentia-usoris/internal/utils"
+)
+
+type groupRepository struct {
+	db *sql.DB
+}
+
+// NewGroupRepository creates a new instance of GroupRepository
+func NewGroupRepository(db *sql.DB) reporitory.GroupRepository {
+	return &groupRepository{
+		db: db,
+	}
+}
+
+// CreateGroup creates a new group
+func (repo *groupRepository) CreateGroup(ctx context.Context, group *entity.Group) error {
+	query := `
+		INSERT INTO groups (
+			name,
+			description,
+			created_at,
+			updated_at
+		)
+		VALUES ($1, $2, $3, $4)
+		RETURNING uuid`
+
+	err := repo.db.QueryRowContext(ctx, query,
+		group.Name,
+		group.Description,
+		group.CreatedAt,
+		group.UpdatedAt,
+	).Scan(&group.UUID)
+
+	if err != nil {
+		if isUniqueViolation(err, "groups_name_key") {
+			return utils.ErrDuplicateGroup
+		}
+
+		logger.FromContext(ctx).WithError(err).Error("Error inserting group")
+		return err
+	}
+
+	return nil
+}
+
+// ListGroups lists the groups ordered by name
+func (repo *groupRepository) ListGroups(ctx context.Context) ([]*entity.Group, error) {
+	query := `
+		SELECT
+			uuid,
+			name,
+			description,
+			created_at,
+			updated_at
+		FROM
+			groups
+		ORDER BY
+			name`
+
+	rows, err := repo.db.QueryContext(ctx, query)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error listing groups")
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanGroups(rows)
+}
+
+// GetGroupByUUID gets a group by UUID
+func (repo *groupRepository) GetGroupByUUID(ctx context.Context, groupUUID string) (*entity.Group, error) {
+	query := `
+		SELECT
+			uuid,
+			name,
+			description,
+			created_at,
+			updated_at
+		FROM
+			groups
+		WHERE
+			uuid = $1`
+
+	group, err := scanGroup(repo.db.QueryRowContext(ctx, query, groupUUID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, utils.ErrGroupNotFound
+		}
+
+		logger.FromContext(ctx).WithError(err).Error("Error getting group by uuid")
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// UpdateGroup updates the name and description of a group
+func (repo *groupRepository) UpdateGroup(ctx context.Context, group *entity.Group) error {
+	query := `
+		UPDATE
+			groups
+		SET
+			name = $2,
+			description = $3,
+			updated_at = $4
+		WHERE
+			uuid = $1`
+
+	result, err := repo.db.ExecContext(ctx, query,
+		group.UUID,
+		group.Name,
+		group.Description,
+		group.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err, "groups_name_key") {
+			return utils.ErrDuplicateGroup
+		}
+
+		logger.FromContext(ctx).WithError(err).Error("Error updating group")
+		return err
+	}
+
+	return expectAffected(result, utils.ErrGroupNotFound)
+}
+
+// DeleteGroup deletes a group, its members and its roles
+func (repo *groupRepository) DeleteGroup(ctx context.Context, groupUUID string) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error beginning transaction")
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM group_members WHERE group_id = $1`, groupUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error deleting group members")
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM group_roles WHERE group_id = $1`, groupUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error deleting group roles")
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM groups WHERE uuid = $1`, groupUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error deleting group")
+		return err
+	}
+
+	if err := expectAffected(result, utils.ErrGroupNotFound); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error committing transaction")
+		return err
+	}
+
+	return nil
+}
+
+// ListGroupMembers lists the members of the group
+func (repo *groupRepository) ListGroupMembers(ctx context.Context, groupUUID string) ([]*entity.User, error) {
+	query := `
+		SELECT
+			u.uuid,
+			u.name,
+			u.email,
+			u.password_hash,
+			u.date_of_birth,
+			u.phone_number,
+			u.is_blocked,
+			u.is_email_verified,
+			u.created_at,
+			u.updated_at,
+			u.deleted_at,
+			u.is_deleted,
+			u.last_login,
+			u.blocked_at,
+			u.blocked_reason
+		FROM
+			group_members gm
+			INNER JOIN users u ON u.uuid = gm.user_id
+		WHERE
+			gm.group_id = $1
+		ORDER BY
+			u.name`
+
+	rows, err := repo.db.QueryContext(ctx, query, groupUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error listing group members")
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanUsers(rows)
+}
+
+// AddMember adds a user to a group
+func (repo *groupRepository) AddMember(ctx context.Context, groupUUID, userUUID string) error {
+	query := `
+		INSERT INTO group_members (
+			group_id,
+			user_id
+		)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING`
+
+	_, err := repo.db.ExecContext(ctx, query, groupUUID, userUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error adding member to group")
+		return err
+	}
+
+	return nil
+}
+
+// RemoveMember removes a user from a group
+func (repo *groupRepository) RemoveMember(ctx context.Context, groupUUID, userUUID string) error {
+	query := `DELETE FROM group_members WHERE group_id = $1 AND user_id = $2`
+
+	result, err := repo.db.ExecContext(ctx, query, groupUUID, userUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error removing member from group")
+		return err
+	}
+
+	return expectAffected(result, utils.ErrGroupMemberNotFound)
+}
+
+// ListGroupRoles lists the roles assigned to the group
+func (repo *groupRepository) ListGroupRoles(ctx context.Context, groupUUID string) ([]*entity.Role, error) {
+	query := `
+		SELECT
+			r.uuid,
+			r.name,
+			r.description,
+			r.created_at,
+			r.updated_at
+		FROM
+			group_roles gr
+			INNER JOIN roles r ON r.uuid = gr.role_id
+		WHERE
+			gr.group_id = $1
+		ORDER BY
+			r.name`
+
+	rows, err := repo.db.QueryContext(ctx, query, groupUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error listing group roles")
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRoles(rows)
+}
+
+// AssignRole assigns a role to a group
+func (repo *groupRepository) AssignRole(ctx context.Context, groupUUID, roleUUID string) error {
+	query := `
+		INSERT INTO group_roles (
+			group_id,
+			role_id
+		)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING`
+
+	_, err := repo.db.ExecContext(ctx, query, groupUUID, roleUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error assigning role to group")
+		return err
+	}
+
+	return nil
+}
+
+// RevokeRole revokes a role from a group
+func (repo *groupRepository) RevokeRole(ctx context.Context, groupUUID, roleUUID string) error {
+	query := `DELETE FROM group_roles WHERE group_id = $1 AND role_id = $2`
+
+	result, err := repo.db.ExecContext(ctx, query, groupUUID, roleUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error revoking role from group")
+		return err
+	}
+
+	return expectAffected(result, utils.ErrGroupRoleNotAssigned)
+}
+
+// scanGroup scans a groups row
+func scanGroup(row rowScanner) (*entity.Group, error) {
+	group := &entity.Group{}
+	var description sql.NullString
+
+	err := row.Scan(
+		&group.UUID,
+		&group.Name,
+		&description,
+		&group.CreatedAt,
+		&group.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	group.Description = description.String
+
+	return group, nil
+}
+
+// scanGroups scans every groups row
+func scanGroups(rows *sql.Rows) ([]*entity.Group, error) {
+	groups := []*entity.Group{}
+	for rows.Next() {
+		group, err := scanGroup(rows)
+		if err != nil {
+			logger.Base().WithError(err).Error("Error scanning group")
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Base().WithError(err).Error("Error iterating groups")
+		return nil, err
+	}
+
+	return groups, nil
+}
@@ -0,0 +1,278 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/edutav/licentia-usoris/infrastructure/logger"
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+	"github.com/edutav/licentia-usoris/internal/domain/reporitory"
+	"github.com/edutav/licentia-usoris/internal/utils"
+	"github.com/lib/pq"
+)
+
+type mfaRepository struct {
+	db *sql.DB
+}
+
+// NewMFARepository creates a new instance of MFARepository
+func NewMFARepository(db *sql.DB) reporitory.MFARepository {
+	return &mfaRepository{
+		db: db,
+	}
+}
+
+// SavePendingMFA saves a pending enrollment of the user, replacing a previous pending one.
+// An enabled enrollment is never replaced.
+func (repo *mfaRepository) SavePendingMFA(ctx context.Context, mfa *entity.UserMFA) error {
+	query := `
+		INSERT INTO user_mfa (
+			user_id,
+			secret_encrypted,
+			created_at,
+			updated_at
+		)
+		VALUES ($1, $2, $3, $3)
+		ON CONFLICT (user_id) DO UPDATE SET
+			secret_encrypted = EXCLUDED.secret_encrypted,
+			last_used_step = 0,
+			created_at = EXCLUDED.created_at,
+			updated_at = EXCLUDED.updated_at
+		WHERE
+			user_mfa.enabled_at IS NULL`
+
+	result, err := repo.db.ExecContext(ctx, query, mfa.UserUUID, mfa.SecretEncrypted, mfa.CreatedAt)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error saving pending mfa")
+		return err
+	}
+
+	return expectAffected(result, utils.ErrMFAAlreadyEnabled)
+}
+
+// GetMFA gets the enrollment of the user
+func (repo *mfaRepository) GetMFA(ctx context.Context, userUUID string) (*entity.UserMFA, error) {
+	query := `
+		SELECT
+			user_id,
+			secret_encrypted,
+			enabled_at,
+			last_used_step,
+			created_at,
+			updated_at
+		FROM
+			user_mfa
+		WHERE
+			user_id = $1`
+
+	mfa := &entity.UserMFA{}
+	var enabledAt sql.NullTime
+
+	err := repo.db.QueryRowContext(ctx, query, userUUID).Scan(
+		&mfa.UserUUID,
+		&mfa.SecretEncrypted,
+		&enabledAt,
+		&mfa.LastUsedStep,
+		&mfa.CreatedAt,
+		&mfa.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, utils.ErrMFANotEnrolled
+		}
+
+		logger.FromContext(ctx).WithError(err).Error("Error getting mfa")
+		return nil, err
+	}
+
+	mfa.EnabledAt = enabledAt.Time
+
+	return mfa, nil
+}
+
+// EnableMFA enables the pending enrollment of the user with the time step of its first code
+func (repo *mfaRepository) EnableMFA(ctx context.Context, userUUID string, step int64, enabledAt time.Time) error {
+	query := `
+		UPDATE
+			user_mfa
+		SET
+			enabled_at = $3,
+			last_used_step = $2,
+			updated_at = $3
+		WHERE
+			user_id = $1
+			AND enabled_at IS NULL`
+
+	result, err := repo.db.ExecContext(ctx, query, userUUID, step, enabledAt)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error enabling mfa")
+		return err
+	}
+
+	return expectAffected(result, utils.ErrMFAAlreadyEnabled)
+}
+
+// UseMFAStep records the time step of a used code. A code of the same or an older
+// step is refused, so a code cannot be replayed while it is still valid.
+func (repo *mfaRepository) UseMFAStep(ctx context.Context, userUUID string, step int64) error {
+	query := `
+		UPDATE
+			user_mfa
+		SET
+			last_used_step = $2
+		WHERE
+			user_id = $1
+			AND enabled_at IS NOT NULL
+			AND last_used_step < $2`
+
+	result, err := repo.db.ExecContext(ctx, query, userUUID, step)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error using mfa step")
+		return err
+	}
+
+	return expectAffected(result, utils.ErrInvalidMFACode)
+}
+
+// DeleteMFA deletes the enrollment of the user, its recovery codes cascading
+func (repo *mfaRepository) DeleteMFA(ctx context.Context, userUUID string) error {
+	query := `
+		DELETE FROM
+			user_mfa
+		WHERE
+			user_id = $1`
+
+	result, err := repo.db.ExecContext(ctx, query, userUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error deleting mfa")
+		return err
+	}
+
+	return expectAffected(result, utils.ErrMFANotEnrolled)
+}
+
+// ReplaceRecoveryCodes replaces the recovery codes of the user with the given hashes
+func (repo *mfaRepository) ReplaceRecoveryCodes(ctx context.Context, userUUID string, codeHashes []string) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error beginning transaction")
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		DELETE FROM
+			mfa_recovery_codes
+		WHERE
+			user_id = $1`
+
+	_, err = tx.ExecContext(ctx, query, userUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error deleting recovery codes")
+		return err
+	}
+
+	query = `
+		INSERT INTO mfa_recovery_codes (
+			user_id,
+			code_hash
+		)
+		SELECT
+			$1,
+			UNNEST($2::TEXT[])`
+
+	_, err = tx.ExecContext(ctx, query, userUUID, pq.Array(codeHashes))
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error inserting recovery codes")
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error committing transaction")
+		return err
+	}
+
+	return nil
+}
+
+// ListUnusedRecoveryCodes lists the recovery codes of the user that were not used
+func (repo *mfaRepository) ListUnusedRecoveryCodes(ctx context.Context, userUUID string) ([]*entity.RecoveryCode, error) {
+	query := `
+		SELECT
+			uuid,
+			user_id,
+			code_hash,
+			created_at
+		FROM
+			mfa_recovery_codes
+		WHERE
+			user_id = $1
+			AND used_at IS NULL`
+
+	rows, err := repo.db.QueryContext(ctx, query, userUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error listing recovery codes")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var codes []*entity.RecoveryCode
+	for rows.Next() {
+		code := &entity.RecoveryCode{}
+		if err := rows.Scan(&code.UUID, &code.UserUUID, &code.CodeHash, &code.CreatedAt); err != nil {
+			logger.FromContext(ctx).WithError(err).Error("Error scanning recovery code")
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error iterating recovery codes")
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// UseRecoveryCode marks the recovery code as used. Only an unused code can be used,
+// so a concurrent use of the same code loses the race.
+func (repo *mfaRepository) UseRecoveryCode(ctx context.Context, codeUUID string) error {
+	query := `
+		UPDATE
+			mfa_recovery_codes
+		SET
+			used_at = NOW()
+		WHERE
+			uuid = $1
+			AND used_at IS NULL`
+
+	result, err := repo.db.ExecContext(ctx, query, codeUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error using recovery code")
+		return err
+	}
+
+	return expectAffected(result, utils.ErrInvalidMFACode)
+}
+
+// CountUnusedRecoveryCodes counts the recovery codes of the user that were not used
+func (repo *mfaRepository) CountUnusedRecoveryCodes(ctx context.Context, userUUID string) (int, error) {
+	query := `
+		SELECT
+			COUNT(*)
+		FROM
+			mfa_recovery_codes
+		WHERE
+			user_id = $1
+			AND used_at IS NULL`
+
+	var count int
+	err := repo.db.QueryRowContext(ctx, query, userUUID).Scan(&count)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error counting recovery codes")
+		return 0, err
+	}
+
+	return count, nil
+}
@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/edutav/licentia-usoris/infrastructure/logger"
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+	"github.com/edutav/licentia-usoris/internal/domain/reporitory"
+	"github.com/edutav/licentia-usoris/internal/utils"
+)
+
+type passwordResetRepository struct {
+	db *sql.DB
+}
+
+// NewPasswordResetRepository creates a new instance of PasswordResetRepository
+func NewPasswordResetRepository(db *sql.DB) reporitory.PasswordResetRepository {
+	return &passwordResetRepository{
+		db: db,
+	}
+}
+
+// CreatePasswordResetToken creates a new password reset token, so only the latest one sent can be used
+func (repo *passwordResetRepository) CreatePasswordResetToken(ctx context.Context, token *entity.PasswordResetToken) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error beginning transaction")
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE
+			password_reset_tokens
+		SET
+			used_at = NOW()
+		WHERE
+			user_id = $1
+			AND used_at IS NULL`
+
+	_, err = tx.ExecContext(ctx, query, token.UserUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error invalidating password reset tokens")
+		return err
+	}
+
+	query = `
+		INSERT INTO password_reset_tokens (
+			user_id,
+			token_hash,
+			expires_at,
+			created_at
+		)
+		VALUES ($1, $2, $3, $4)
+		RETURNING uuid`
+
+	err = tx.QueryRowContext(ctx, query,
+		token.UserUUID,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.CreatedAt,
+	).Scan(&token.UUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error inserting password reset token")
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error committing transaction")
+		return err
+	}
+
+	return nil
+}
+
+// GetPasswordResetTokenByHash gets a password reset token by the hash of its value
+func (repo *passwordResetRepository) GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error) {
+	query := `
+		SELECT
+			uuid,
+			user_id,
+			token_hash,
+			expires_at,
+			created_at,
+			used_at
+		FROM
+			password_reset_tokens
+		WHERE
+			token_hash = $1
+		LIMIT 1`
+
+	token := &entity.PasswordResetToken{}
+	var usedAt sql.NullTime
+
+	err := repo.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&token.UUID,
+		&token.UserUUID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+		&usedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, utils.ErrInvalidPasswordResetToken
+		}
+
+		logger.FromContext(ctx).WithError(err).Error("Error getting password reset token by hash")
+		return nil, err
+	}
+
+	token.UsedAt = usedAt.Time
+
+	return token, nil
+}
+
+// ResetPassword marks the token as used and sets the new password of its user
+func (repo *passwordResetRepository) ResetPassword(
+	ctx context.Context, token *entity.PasswordResetToken, passwordHash string, updatedAt time.Time,
+) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error beginning transaction")
+		return err
+	}
+	defer tx.Rollback()
+
+	// Only an unused token can be consumed, so a concurrent use of the same token loses the race
+	query := `
+		UPDATE
+			password_reset_tokens
+		SET
+			used_at = NOW()
+		WHERE
+			uuid = $1
+			AND used_at IS NULL
+			AND expires_at > NOW()`
+
+	result, err := tx.ExecContext(ctx, query, token.UUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error using password reset token")
+		return err
+	}
+
+	if err := expectAffected(result, utils.ErrInvalidPasswordResetToken); err != nil {
+		return err
+	}
+
+	err = setPassword(ctx, tx, token.UserUUID, passwordHash, updatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error committing transaction")
+		return err
+	}
+
+	return nil
+}
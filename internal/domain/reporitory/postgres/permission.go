@@ -0,0 +1,229 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/edutav/licentia-usoris/infrastructure/logger"
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+	"github.com/edutav/licentia-usoris/internal/domain/reporitory"
+	"github.com/edutav/licentia-usoris/internal/utils"
+	"github.com/lib/pq"
+)
+
+type permissionRepository struct {
+	db *sql.DB
+}
+
+// NewPermissionRepository creates a new instance of PermissionRepository
+func NewPermissionRepository(db *sql.DB) reporitory.PermissionRepository {
+	return &permissionRepository{
+		db: db,
+	}
+}
+
+// CreatePermission creates a new permission
+func (repo *permissionRepository) CreatePermission(ctx context.Context, permission *entity.Permission) error {
+	query := `
+		INSERT INTO permissions (
+			name,
+			description,
+			created_at,
+			updated_at
+		)
+		VALUES ($1, $2, $3, $4)
+		RETURNING uuid`
+
+	err := repo.db.QueryRowContext(ctx, query,
+		permission.Name,
+		permission.Description,
+		permission.CreatedAt,
+		permission.UpdatedAt,
+	).Scan(&permission.UUID)
+
+	if err != nil {
+		if isUniqueViolation(err, "permissions_name_key") {
+			return utils.ErrDuplicatePermission
+		}
+
+		logger.FromContext(ctx).WithError(err).Error("Error inserting permission")
+		return err
+	}
+
+	return nil
+}
+
+// ListPermissions lists the permissions ordered by name
+func (repo *permissionRepository) ListPermissions(ctx context.Context) ([]*entity.Permission, error) {
+	query := `
+		SELECT
+			uuid,
+			name,
+			description,
+			created_at,
+			updated_at
+		FROM
+			permissions
+		ORDER BY
+			name`
+
+	rows, err := repo.db.QueryContext(ctx, query)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error listing permissions")
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanPermissions(rows)
+}
+
+// GetPermissionByUUID gets a permission by UUID
+func (repo *permissionRepository) GetPermissionByUUID(ctx context.Context, permissionUUID string) (*entity.Permission, error) {
+	query := `
+		SELECT
+			uuid,
+			name,
+			description,
+			created_at,
+			updated_at
+		FROM
+			permissions
+		WHERE
+			uuid = $1`
+
+	permission, err := scanPermission(repo.db.QueryRowContext(ctx, query, permissionUUID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, utils.ErrPermissionNotFound
+		}
+
+		logger.FromContext(ctx).WithError(err).Error("Error getting permission by uuid")
+		return nil, err
+	}
+
+	return permission, nil
+}
+
+// UpdatePermission updates the name and description of a permission
+func (repo *permissionRepository) UpdatePermission(ctx context.Context, permission *entity.Permission) error {
+	query := `
+		UPDATE
+			permissions
+		SET
+			name = $2,
+			description = $3,
+			updated_at = $4
+		WHERE
+			uuid = $1`
+
+	result, err := repo.db.ExecContext(ctx, query,
+		permission.UUID,
+		permission.Name,
+		permission.Description,
+		permission.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err, "permissions_name_key") {
+			return utils.ErrDuplicatePermission
+		}
+
+		logger.FromContext(ctx).WithError(err).Error("Error updating permission")
+		return err
+	}
+
+	return expectAffected(result, utils.ErrPermissionNotFound)
+}
+
+// DeletePermission deletes a permission and detaches it from every role
+func (repo *permissionRepository) DeletePermission(ctx context.Context, permissionUUID string) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error beginning transaction")
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM role_permissions WHERE permission_id = $1`, permissionUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error deleting permission grants")
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM permissions WHERE uuid = $1`, permissionUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error deleting permission")
+		return err
+	}
+
+	if err := expectAffected(result, utils.ErrPermissionNotFound); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error committing transaction")
+		return err
+	}
+
+	return nil
+}
+
+// scanPermission scans a permissions row
+func scanPermission(row rowScanner) (*entity.Permission, error) {
+	permission := &entity.Permission{}
+	var description sql.NullString
+
+	err := row.Scan(
+		&permission.UUID,
+		&permission.Name,
+		&description,
+		&permission.CreatedAt,
+		&permission.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	permission.Description = description.String
+
+	return permission, nil
+}
+
+// scanPermissions scans every permissions row
+func scanPermissions(rows *sql.Rows) ([]*entity.Permission, error) {
+	permissions := []*entity.Permission{}
+	for rows.Next() {
+		permission, err := scanPermission(rows)
+		if err != nil {
+			logger.Base().WithError(err).Error("Error scanning permission")
+			return nil, err
+		}
+		permissions = append(permissions, permission)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Base().WithError(err).Error("Error iterating permissions")
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+// isUniqueViolation checks if the error is a unique violation of the constraint
+func isUniqueViolation(err error, constraint string) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == "23505" && pqErr.Constraint == constraint
+}
+
+// expectAffected returns notFound when the statement did not affect any row
+func expectAffected(result sql.Result, notFound error) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return notFound
+	}
+
+	return nil
+}
@@ -0,0 +1,217 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/edutav/licentia-usoris/infrastructure/logger"
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+	"github.com/edutav/licentia-usoris/internal/domain/reporitory"
+	"github.com/edutav/licentia-usoris/internal/utils"
+)
+
+type refreshTokenRepository struct {
+	db *sql.DB
+}
+
+// NewRefreshTokenRepository creates a new instance of RefreshTokenRepository
+func NewRefreshTokenRepository(db *sql.DB) reporitory.RefreshTokenRepository {
+	return &refreshTokenRepository{
+		db: db,
+	}
+}
+
+// querier is implemented by *sql.DB and *sql.Tx
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// CreateRefreshToken creates a new refresh token
+func (repo *refreshTokenRepository) CreateRefreshToken(ctx context.Context, token *entity.RefreshToken) error {
+	return insertRefreshToken(ctx, repo.db, token)
+}
+
+// insertRefreshToken inserts the token, using its own UUID as family ID when none is given
+func insertRefreshToken(ctx context.Context, q querier, token *entity.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (
+			uuid,
+			user_id,
+			family_id,
+			token_hash,
+			expires_at,
+			created_at
+		)
+		SELECT
+			new.id,
+			$1,
+			COALESCE(NULLIF($2, '')::uuid, new.id),
+			$3,
+			$4,
+			$5
+		FROM
+			(SELECT uuid_generate_v4() AS id) new
+		RETURNING
+			uuid,
+			family_id`
+
+	err := q.QueryRowContext(ctx, query,
+		token.UserUUID,
+		token.FamilyID,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.CreatedAt,
+	).Scan(&token.UUID, &token.FamilyID)
+
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error inserting refresh token")
+		return err
+	}
+
+	return nil
+}
+
+// GetRefreshTokenByHash gets a refresh token by the hash of its value
+func (repo *refreshTokenRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	query := `
+		SELECT
+			uuid,
+			user_id,
+			family_id,
+			token_hash,
+			expires_at,
+			created_at,
+			rotated_at,
+			revoked_at,
+			replaced_by
+		FROM
+			refresh_tokens
+		WHERE
+			token_hash = $1
+		LIMIT 1`
+
+	token := &entity.RefreshToken{}
+	var (
+		rotatedAt  sql.NullTime
+		revokedAt  sql.NullTime
+		replacedBy sql.NullString
+	)
+
+	err := repo.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&token.UUID,
+		&token.UserUUID,
+		&token.FamilyID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+		&rotatedAt,
+		&revokedAt,
+		&replacedBy,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, utils.ErrInvalidRefreshToken
+		}
+		logger.FromContext(ctx).WithError(err).Error("Error getting refresh token by hash")
+		return nil, err
+	}
+
+	token.RotatedAt = rotatedAt.Time
+	token.RevokedAt = revokedAt.Time
+	token.ReplacedBy = replacedBy.String
+
+	return token, nil
+}
+
+// RotateRefreshToken marks the current token as rotated and creates the next one in the same family
+func (repo *refreshTokenRepository) RotateRefreshToken(
+	ctx context.Context, current *entity.RefreshToken, next *entity.RefreshToken,
+) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error beginning transaction")
+		return err
+	}
+	defer tx.Rollback()
+
+	next.FamilyID = current.FamilyID
+	err = insertRefreshToken(ctx, tx, next)
+	if err != nil {
+		return err
+	}
+
+	// Only an active token can be rotated, so a concurrent use of the same token loses the race
+	query := `
+		UPDATE
+			refresh_tokens
+		SET
+			rotated_at = NOW(),
+			replaced_by = $2
+		WHERE
+			uuid = $1
+			AND rotated_at IS NULL
+			AND revoked_at IS NULL`
+
+	result, err := tx.ExecContext(ctx, query, current.UUID, next.UUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error rotating refresh token")
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return utils.ErrRefreshTokenReused
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error committing transaction")
+		return err
+	}
+
+	return nil
+}
+
+// RevokeRefreshTokenFamily revokes every refresh token of a family
+func (repo *refreshTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	query := `
+		UPDATE
+			refresh_tokens
+		SET
+			revoked_at = NOW()
+		WHERE
+			family_id = $1
+			AND revoked_at IS NULL`
+
+	_, err := repo.db.ExecContext(ctx, query, familyID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error revoking refresh token family")
+		return err
+	}
+
+	return nil
+}
+
+// RevokeUserRefreshTokens revokes every active refresh token of the user
+func (repo *refreshTokenRepository) RevokeUserRefreshTokens(ctx context.Context, userUUID string) error {
+	query := `
+		UPDATE
+			refresh_tokens
+		SET
+			revoked_at = NOW()
+		WHERE
+			user_id = $1
+			AND revoked_at IS NULL`
+
+	_, err := repo.db.ExecContext(ctx, query, userUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error revoking user refresh tokens")
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,338 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/edutav/licentia-usoris/infrastructure/logger"
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+	"github.com/edutav/licentia-usoris/internal/domain/reporitory"
+	"github.com/edutav/licentia-usoris/internal/utils"
+)
+
+type roleRepository struct {
+	db *sql.DB
+}
+
+// NewRoleRepository creates a new instance of RoleRepository
+func NewRoleRepository(db *sql.DB) reporitory.RoleRepository {
+	return &roleRepository{
+		db: db,
+	}
+}
+
+// CreateRole creates a new role
+func (repo *roleRepository) CreateRole(ctx context.Context, role *entity.Role) error {
+	query := `
+		INSERT INTO roles (
+			name,
+			description,
+			created_at,
+			updated_at
+		)
+		VALUES ($1, $2, $3, $4)
+		RETURNING uuid`
+
+	err := repo.db.QueryRowContext(ctx, query,
+		role.Name,
+		role.Description,
+		role.CreatedAt,
+		role.UpdatedAt,
+	).Scan(&role.UUID)
+
+	if err != nil {
+		if isUniqueViolation(err, "roles_name_key") {
+			return utils.ErrDuplicateRole
+		}
+
+		logger.FromContext(ctx).WithError(err).Error("Error inserting role")
+		return err
+	}
+
+	return nil
+}
+
+// ListRoles lists the roles ordered by name
+func (repo *roleRepository) ListRoles(ctx context.Context) ([]*entity.Role, error) {
+	query := `
+		SELECT
+			uuid,
+			name,
+			description,
+			created_at,
+			updated_at
+		FROM
+			roles
+		ORDER BY
+			name`
+
+	rows, err := repo.db.QueryContext(ctx, query)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error listing roles")
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRoles(rows)
+}
+
+// GetRoleByUUID gets a role by UUID
+func (repo *roleRepository) GetRoleByUUID(ctx context.Context, roleUUID string) (*entity.Role, error) {
+	query := `
+		SELECT
+			uuid,
+			name,
+			description,
+			created_at,
+			updated_at
+		FROM
+			roles
+		WHERE
+			uuid = $1`
+
+	role, err := scanRole(repo.db.QueryRowContext(ctx, query, roleUUID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, utils.ErrRoleNotFound
+		}
+
+		logger.FromContext(ctx).WithError(err).Error("Error getting role by uuid")
+		return nil, err
+	}
+
+	return role, nil
+}
+
+// UpdateRole updates the name and description of a role
+func (repo *roleRepository) UpdateRole(ctx context.Context, role *entity.Role) error {
+	query := `
+		UPDATE
+			roles
+		SET
+			name = $2,
+			description = $3,
+			updated_at = $4
+		WHERE
+			uuid = $1`
+
+	result, err := repo.db.ExecContext(ctx, query,
+		role.UUID,
+		role.Name,
+		role.Description,
+		role.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err, "roles_name_key") {
+			return utils.ErrDuplicateRole
+		}
+
+		logger.FromContext(ctx).WithError(err).Error("Error updating role")
+		return err
+	}
+
+	return expectAffected(result, utils.ErrRoleNotFound)
+}
+
+// DeleteRole deletes a role, its permissions and its user and group assignments
+func (repo *roleRepository) DeleteRole(ctx context.Context, roleUUID string) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error beginning transaction")
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM role_permissions WHERE role_id = $1`, roleUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error deleting role permissions")
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM user_roles WHERE role_id = $1`, roleUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error deleting role assignments")
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM group_roles WHERE role_id = $1`, roleUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error deleting group role assignments")
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM roles WHERE uuid = $1`, roleUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error deleting role")
+		return err
+	}
+
+	if err := expectAffected(result, utils.ErrRoleNotFound); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error committing transaction")
+		return err
+	}
+
+	return nil
+}
+
+// ListRolePermissions lists the permissions attached to the role
+func (repo *roleRepository) ListRolePermissions(ctx context.Context, roleUUID string) ([]*entity.Permission, error) {
+	query := `
+		SELECT
+			p.uuid,
+			p.name,
+			p.description,
+			p.created_at,
+			p.updated_at
+		FROM
+			role_permissions rp
+			INNER JOIN permissions p ON p.uuid = rp.permission_id
+		WHERE
+			rp.role_id = $1
+		ORDER BY
+			p.name`
+
+	rows, err := repo.db.QueryContext(ctx, query, roleUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error listing role permissions")
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanPermissions(rows)
+}
+
+// AttachPermission attaches a permission to a role
+func (repo *roleRepository) AttachPermission(ctx context.Context, roleUUID, permissionUUID string) error {
+	query := `
+		INSERT INTO role_permissions (
+			role_id,
+			permission_id
+		)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING`
+
+	_, err := repo.db.ExecContext(ctx, query, roleUUID, permissionUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error attaching permission to role")
+		return err
+	}
+
+	return nil
+}
+
+// DetachPermission detaches a permission from a role
+func (repo *roleRepository) DetachPermission(ctx context.Context, roleUUID, permissionUUID string) error {
+	query := `DELETE FROM role_permissions WHERE role_id = $1 AND permission_id = $2`
+
+	result, err := repo.db.ExecContext(ctx, query, roleUUID, permissionUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error detaching permission from role")
+		return err
+	}
+
+	return expectAffected(result, utils.ErrPermissionNotAttached)
+}
+
+// ListUserRoles lists the roles assigned to the user
+func (repo *roleRepository) ListUserRoles(ctx context.Context, userUUID string) ([]*entity.Role, error) {
+	query := `
+		SELECT
+			r.uuid,
+			r.name,
+			r.description,
+			r.created_at,
+			r.updated_at
+		FROM
+			user_roles ur
+			INNER JOIN roles r ON r.uuid = ur.role_id
+		WHERE
+			ur.user_id = $1
+		ORDER BY
+			r.name`
+
+	rows, err := repo.db.QueryContext(ctx, query, userUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error listing user roles")
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRoles(rows)
+}
+
+// AssignRole assigns a role to a user
+func (repo *roleRepository) AssignRole(ctx context.Context, userUUID, roleUUID string) error {
+	query := `
+		INSERT INTO user_roles (
+			user_id,
+			role_id
+		)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING`
+
+	_, err := repo.db.ExecContext(ctx, query, userUUID, roleUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error assigning role to user")
+		return err
+	}
+
+	return nil
+}
+
+// RevokeRole revokes a role from a user
+func (repo *roleRepository) RevokeRole(ctx context.Context, userUUID, roleUUID string) error {
+	query := `DELETE FROM user_roles WHERE user_id = $1 AND role_id = $2`
+
+	result, err := repo.db.ExecContext(ctx, query, userUUID, roleUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error revoking role from user")
+		return err
+	}
+
+	return expectAffected(result, utils.ErrRoleNotAssigned)
+}
+
+// scanRole scans a roles row
+func scanRole(row rowScanner) (*entity.Role, error) {
+	role := &entity.Role{}
+	var description sql.NullString
+
+	err := row.Scan(
+		&role.UUID,
+		&role.Name,
+		&description,
+		&role.CreatedAt,
+		&role.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	role.Description = description.String
+
+	return role, nil
+}
+
+// scanRoles scans every roles row
+func scanRoles(rows *sql.Rows) ([]*entity.Role, error) {
+	roles := []*entity.Role{}
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			logger.Base().WithError(err).Error("Error scanning role")
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Base().WithError(err).Error("Error iterating roles")
+		return nil, err
+	}
+
+	return roles, nil
+}
@@ -5,8 +5,11 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"log"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/edutav/licentia-usoris/infrastructure/logger"
 	"github.com/edutav/licentia-usoris/internal/domain/entity"
 	"github.com/edutav/licentia-usoris/internal/domain/reporitory"
 	"github.com/edutav/licentia-usoris/internal/utils"
@@ -24,13 +27,14 @@ func NewUserRepository(db *sql.DB) reporitory.UserRepository {
 	}
 }
 
-// PreRegisterUser pre-registers a new user
+// PreRegisterUser pre-registers a new user, replacing a pending pre-registration of the same email.
+// A verified pre-registration is never replaced.
 func (repo *userRepository) PreRegisterUser(
 	ctx context.Context, preRegistration *entity.PreRegistration,
 ) error {
 	tx, err := repo.db.BeginTx(ctx, nil)
 	if err != nil {
-		log.Printf("Error beginning transaction: %v", err)
+		logger.FromContext(ctx).WithError(err).Error("Error beginning transaction")
 		return err
 	}
 	defer tx.Rollback()
@@ -39,30 +43,35 @@ func (repo *userRepository) PreRegisterUser(
 		INSERT INTO pre_registrations (
 			email, 
 			password_hash, 
-			code_otp, 
 			user_data, 
 			expires_at, 
 			is_verified, 
 			created_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (email) DO UPDATE SET
+			password_hash = EXCLUDED.password_hash,
+			user_data = EXCLUDED.user_data,
+			expires_at = EXCLUDED.expires_at,
+			created_at = EXCLUDED.created_at
+		WHERE
+			pre_registrations.is_verified = FALSE
 	`
 
 	userDataJSON, err := json.Marshal(preRegistration.UserData)
 	if err != nil {
-		log.Printf("Error marshalling user data: %v", err)
+		logger.FromContext(ctx).WithError(err).Error("Error marshalling user data")
 		return err
 	}
 
-	err = tx.QueryRowContext(ctx, query,
+	result, err := tx.ExecContext(ctx, query,
 		preRegistration.Email,
 		preRegistration.PasswordHash,
-		preRegistration.CodeOTP,
 		userDataJSON,
 		preRegistration.ExpiresAt,
 		preRegistration.IsVerified,
 		preRegistration.CreatedAt,
-	).Err()
+	)
 
 	if err != nil {
 		pqErr, ok := err.(*pq.Error)
@@ -75,12 +84,16 @@ func (repo *userRepository) PreRegisterUser(
 			}
 		}
 
-		log.Printf("Error inserting pre-registration: %v", err)
+		logger.FromContext(ctx).WithError(err).Error("Error inserting pre-registration")
+		return err
+	}
+
+	if err := expectAffected(result, utils.ErrDuplicateEmail); err != nil {
 		return err
 	}
 
 	if err := tx.Commit(); err != nil {
-		log.Printf("Error committing transaction: %v", err)
+		logger.FromContext(ctx).WithError(err).Error("Error committing transaction")
 		return err
 	}
 
@@ -103,7 +116,9 @@ func (repo *userRepository) GetUserByEmail(ctx context.Context, email string) (*
 			updated_at, 
 			deleted_at,
 			is_deleted, 
-			last_login
+			last_login,
+			blocked_at,
+			blocked_reason
 		FROM
 			users
 		WHERE
@@ -111,30 +126,52 @@ func (repo *userRepository) GetUserByEmail(ctx context.Context, email string) (*
 		LIMIT 1
 	`
 
-	user := &entity.User{}
+	user, err := scanUser(repo.db.QueryRowContext(ctx, query, email))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, utils.ErrUserNotFound
+		}
 
-	err := repo.db.QueryRowContext(ctx, query, email).Scan(
-		&user.UUID,
-		&user.Name,
-		&user.Email,
-		&user.PasswordHash,
-		&user.DOB,
-		&user.PhoneNumber,
-		&user.IsBlocked,
-		&user.IsEmailVerified,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-		&user.DeletedAt,
-		&user.IsDeleted,
-		&user.LastLogin,
-	)
+		logger.FromContext(ctx).WithError(err).Error("Error getting user by email")
+		return nil, err
+	}
+
+	return user, err
+}
+
+// GetUserByUUID gets a user by UUID
+func (repo *userRepository) GetUserByUUID(ctx context.Context, userUUID string) (*entity.User, error) {
+	query := `
+		SELECT 
+			uuid, 
+			name, 
+			email, 
+			password_hash, 
+			date_of_birth, 
+			phone_number,
+			is_blocked, 
+			is_email_verified, 
+			created_at, 
+			updated_at, 
+			deleted_at,
+			is_deleted, 
+			last_login,
+			blocked_at,
+			blocked_reason
+		FROM
+			users
+		WHERE
+			uuid = $1
+		LIMIT 1
+	`
 
+	user, err := scanUser(repo.db.QueryRowContext(ctx, query, userUUID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, utils.ErrUserNotFound
 		}
 
-		log.Printf("Error getting user by email: %v", err)
+		logger.FromContext(ctx).WithError(err).Error("Error getting user by uuid")
 		return nil, err
 	}
 
@@ -145,7 +182,7 @@ func (repo *userRepository) GetUserByEmail(ctx context.Context, email string) (*
 func (repo *userRepository) CreateUser(ctx context.Context, user *entity.User) error {
 	tx, err := repo.db.BeginTx(ctx, nil)
 	if err != nil {
-		log.Printf("Error beginning transaction: %v", err)
+		logger.FromContext(ctx).WithError(err).Error("Error beginning transaction")
 		return err
 	}
 	defer tx.Rollback()
@@ -192,26 +229,25 @@ func (repo *userRepository) CreateUser(ctx context.Context, user *entity.User) e
 			}
 		}
 
-		log.Printf("Error inserting user: %v", err)
+		logger.FromContext(ctx).WithError(err).Error("Error inserting user")
 		return err
 	}
 
 	if err := tx.Commit(); err != nil {
-		log.Printf("Error committing transaction: %v", err)
+		logger.FromContext(ctx).WithError(err).Error("Error committing transaction")
 		return err
 	}
 
 	return nil
 }
 
-// GetPreRegisteredByEmailAndOTPCode gets a pre-registered user by email and OTP code
-func (repo *userRepository) GetPreRegisteredByEmailAndOTPCode(ctx context.Context, email, otpCode string) (*entity.PreRegistration, error) {
+// GetPreRegistrationByEmail gets a pre-registered user by email
+func (repo *userRepository) GetPreRegistrationByEmail(ctx context.Context, email string) (*entity.PreRegistration, error) {
 	query := `
 		SELECT
 			uuid,
 			email,
 			password_hash,
-			code_otp,
 			user_data,
 			expires_at,
 			is_verified,
@@ -229,7 +265,6 @@ func (repo *userRepository) GetPreRegisteredByEmailAndOTPCode(ctx context.Contex
 		&preRegistration.UUID,
 		&preRegistration.Email,
 		&preRegistration.PasswordHash,
-		&preRegistration.CodeOTP,
 		&userDataJSON,
 		&preRegistration.ExpiresAt,
 		&preRegistration.IsVerified,
@@ -240,13 +275,13 @@ func (repo *userRepository) GetPreRegisteredByEmailAndOTPCode(ctx context.Contex
 		if err == sql.ErrNoRows {
 			return nil, utils.ErrPreRegistredUserNotFound
 		}
-		log.Printf("Error getting pre-registered user by email and OTP code: %v", err)
+		logger.FromContext(ctx).WithError(err).Error("Error getting pre-registered user by email")
 		return nil, err
 	}
 
 	err = json.Unmarshal(userDataJSON, &preRegistration.UserData)
 	if err != nil {
-		log.Printf("Error unmarshalling user data: %v", err)
+		logger.FromContext(ctx).WithError(err).Error("Error unmarshalling user data")
 		return nil, err
 	}
 
@@ -272,7 +307,7 @@ func (repo *userRepository) UpdateUserIsVerified(ctx context.Context, email stri
 	_, err = tx.ExecContext(ctx, query, email)
 	if err != nil {
 		tx.Rollback()
-		log.Printf("Error updating user is verified: %v", err)
+		logger.FromContext(ctx).WithError(err).Error("Error updating user is verified")
 		return err
 	}
 
@@ -282,3 +317,540 @@ func (repo *userRepository) UpdateUserIsVerified(ctx context.Context, email stri
 
 	return err
 }
+
+// DeleteExpiredPreRegistrations deletes the pending pre-registrations that expired before the given time
+func (repo *userRepository) DeleteExpiredPreRegistrations(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		DELETE FROM
+			pre_registrations
+		WHERE
+			expires_at <= $1
+			AND is_verified = FALSE`
+
+	result, err := repo.db.ExecContext(ctx, query, before)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error deleting expired pre-registrations")
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// userRoleIDsQuery selects the roles assigned to the user $1 and the roles inherited from its groups
+const userRoleIDsQuery = `
+			SELECT role_id FROM user_roles WHERE user_id = $1
+			UNION
+			SELECT gr.role_id
+			FROM group_members gm
+			INNER JOIN group_roles gr ON gr.group_id = gm.group_id
+			WHERE gm.user_id = $1`
+
+// GetUserRoles gets the names of the roles assigned to the user, directly or through its groups
+func (repo *userRepository) GetUserRoles(ctx context.Context, userUUID string) ([]string, error) {
+	query := `
+		SELECT DISTINCT
+			r.name
+		FROM
+			(` + userRoleIDsQuery + `) ur
+			INNER JOIN roles r ON r.uuid = ur.role_id
+		ORDER BY
+			r.name`
+
+	rows, err := repo.db.QueryContext(ctx, query, userUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error getting user roles")
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []string{}
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			logger.FromContext(ctx).WithError(err).Error("Error scanning user role")
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error iterating user roles")
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// GetUserPermissions gets the names of the permissions granted to the user through its roles.
+// Blocked and deleted users have no permissions.
+func (repo *userRepository) GetUserPermissions(ctx context.Context, userUUID string) ([]string, error) {
+	query := `
+		SELECT DISTINCT
+			p.name
+		FROM
+			(` + userRoleIDsQuery + `) ur
+			INNER JOIN role_permissions rp ON rp.role_id = ur.role_id
+			INNER JOIN permissions p ON p.uuid = rp.permission_id
+		WHERE
+			EXISTS (SELECT 1 FROM users WHERE uuid = $1 AND NOT is_blocked AND NOT is_deleted)
+		ORDER BY
+			p.name`
+
+	rows, err := repo.db.QueryContext(ctx, query, userUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error getting user permissions")
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := []string{}
+	for rows.Next() {
+		var permission string
+		if err := rows.Scan(&permission); err != nil {
+			logger.FromContext(ctx).WithError(err).Error("Error scanning user permission")
+			return nil, err
+		}
+		permissions = append(permissions, permission)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error iterating user permissions")
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+// UpdateLastLogin updates the user last login
+func (repo *userRepository) UpdateLastLogin(ctx context.Context, userUUID string, lastLogin time.Time) error {
+	query := `
+		UPDATE
+			users
+		SET
+			last_login = $2
+		WHERE
+			uuid = $1`
+
+	_, err := repo.db.ExecContext(ctx, query, userUUID, lastLogin)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error updating user last login")
+		return err
+	}
+
+	return nil
+}
+
+// UpdatePassword sets the password of the user, keeping the previous one in its password history
+func (repo *userRepository) UpdatePassword(ctx context.Context, userUUID, passwordHash string, updatedAt time.Time) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error beginning transaction")
+		return err
+	}
+	defer tx.Rollback()
+
+	err = setPassword(ctx, tx, userUUID, passwordHash, updatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error committing transaction")
+		return err
+	}
+
+	return nil
+}
+
+// GetPasswordHistory gets the hashes of the last previous passwords of the user, the most recent first
+func (repo *userRepository) GetPasswordHistory(ctx context.Context, userUUID string, limit int) ([]string, error) {
+	query := `
+		SELECT
+			password_hash
+		FROM
+			password_history
+		WHERE
+			user_id = $1
+		ORDER BY
+			created_at DESC
+		LIMIT $2`
+
+	rows, err := repo.db.QueryContext(ctx, query, userUUID, limit)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error getting password history")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hashes []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			logger.FromContext(ctx).WithError(err).Error("Error scanning password history")
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error iterating password history")
+		return nil, err
+	}
+
+	return hashes, nil
+}
+
+// setPassword moves the current password of the user to its history and sets the new one
+func setPassword(ctx context.Context, tx *sql.Tx, userUUID, passwordHash string, updatedAt time.Time) error {
+	query := `
+		INSERT INTO password_history (
+			user_id,
+			password_hash,
+			created_at
+		)
+		SELECT
+			uuid,
+			password_hash,
+			$2
+		FROM
+			users
+		WHERE
+			uuid = $1`
+
+	_, err := tx.ExecContext(ctx, query, userUUID, updatedAt)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error inserting password history")
+		return err
+	}
+
+	query = `
+		UPDATE
+			users
+		SET
+			password_hash = $2,
+			updated_at = $3
+		WHERE
+			uuid = $1`
+
+	result, err := tx.ExecContext(ctx, query, userUUID, passwordHash, updatedAt)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error updating user password")
+		return err
+	}
+
+	return expectAffected(result, utils.ErrUserNotFound)
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanUser scans a users row, accepting NULL in the optional columns
+func scanUser(row rowScanner) (*entity.User, error) {
+	user := &entity.User{}
+
+	var (
+		dob           sql.NullTime
+		phoneNumber   sql.NullString
+		deletedAt     sql.NullTime
+		lastLogin     sql.NullTime
+		blockedAt     sql.NullTime
+		blockedReason sql.NullString
+	)
+
+	err := row.Scan(
+		&user.UUID,
+		&user.Name,
+		&user.Email,
+		&user.PasswordHash,
+		&dob,
+		&phoneNumber,
+		&user.IsBlocked,
+		&user.IsEmailVerified,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&deletedAt,
+		&user.IsDeleted,
+		&lastLogin,
+		&blockedAt,
+		&blockedReason,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	user.DOB = dob.Time
+	user.PhoneNumber = phoneNumber.String
+	user.DeletedAt = deletedAt.Time
+	user.LastLogin = lastLogin.Time
+	user.BlockedAt = blockedAt.Time
+	user.BlockedReason = blockedReason.String
+
+	return user, nil
+}
+
+// ListUsers lists the users matching the filter
+func (repo *userRepository) ListUsers(ctx context.Context, filter reporitory.UserFilter) ([]*entity.User, error) {
+	where, args := userFilterConditions(filter)
+
+	sortBy := "created_at"
+	if slices.Contains(reporitory.UserSortFields, filter.SortBy) {
+		sortBy = filter.SortBy
+	}
+
+	direction := "ASC"
+	if filter.SortDesc {
+		direction = "DESC"
+	}
+
+	query := `
+		SELECT
+			uuid,
+			name,
+			email,
+			password_hash,
+			date_of_birth,
+			phone_number,
+			is_blocked,
+			is_email_verified,
+			created_at,
+			updated_at,
+			deleted_at,
+			is_deleted,
+			last_login,
+			blocked_at,
+			blocked_reason
+		FROM
+			users` + where + `
+		ORDER BY
+			` + sortBy + ` ` + direction + ` NULLS LAST, uuid`
+
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf("\n\t\tLIMIT $%d", len(args))
+	}
+
+	if filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf("\n\t\tOFFSET $%d", len(args))
+	}
+
+	rows, err := repo.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error listing users")
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanUsers(rows)
+}
+
+// CountUsers counts the users matching the filter
+func (repo *userRepository) CountUsers(ctx context.Context, filter reporitory.UserFilter) (int, error) {
+	where, args := userFilterConditions(filter)
+
+	var count int
+	err := repo.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`+where, args...).Scan(&count)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error counting users")
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// UpdateUser updates the given profile fields of the user, leaving the other columns untouched
+func (repo *userRepository) UpdateUser(ctx context.Context, userUUID string, update reporitory.UserUpdate) error {
+	args := []any{userUUID, update.UpdatedAt}
+	sets := []string{"updated_at = $2"}
+
+	set := func(column string, value any) {
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+
+	if update.Name != nil {
+		set("name", *update.Name)
+	}
+	if update.Email != nil {
+		set("email", *update.Email)
+	}
+	if update.DOB != nil {
+		set("date_of_birth", sql.NullTime{Time: *update.DOB, Valid: !update.DOB.IsZero()})
+	}
+	if update.PhoneNumber != nil {
+		set("phone_number", sql.NullString{String: *update.PhoneNumber, Valid: *update.PhoneNumber != ""})
+	}
+	if update.IsEmailVerified != nil {
+		set("is_email_verified", *update.IsEmailVerified)
+	}
+
+	query := `UPDATE users SET ` + strings.Join(sets, ", ") + ` WHERE uuid = $1`
+
+	result, err := repo.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		if isUniqueViolation(err, "users_email_key") {
+			return utils.ErrDuplicateEmail
+		}
+
+		logger.FromContext(ctx).WithError(err).Error("Error updating user")
+		return err
+	}
+
+	return expectAffected(result, utils.ErrUserNotFound)
+}
+
+// DeleteUser soft-deletes the user unless it is already deleted
+func (repo *userRepository) DeleteUser(ctx context.Context, userUUID string, deletedAt time.Time) error {
+	query := `
+		UPDATE
+			users
+		SET
+			is_deleted = TRUE,
+			deleted_at = $2,
+			updated_at = $2
+		WHERE
+			uuid = $1
+			AND NOT is_deleted`
+
+	result, err := repo.db.ExecContext(ctx, query, userUUID, deletedAt)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error deleting user")
+		return err
+	}
+
+	return expectAffected(result, utils.ErrUserAlreadyDeleted)
+}
+
+// RestoreUser restores the user if it is soft-deleted
+func (repo *userRepository) RestoreUser(ctx context.Context, userUUID string, updatedAt time.Time) error {
+	query := `
+		UPDATE
+			users
+		SET
+			is_deleted = FALSE,
+			deleted_at = NULL,
+			updated_at = $2
+		WHERE
+			uuid = $1
+			AND is_deleted`
+
+	result, err := repo.db.ExecContext(ctx, query, userUUID, updatedAt)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error restoring user")
+		return err
+	}
+
+	return expectAffected(result, utils.ErrUserNotDeleted)
+}
+
+// BlockUser blocks the user with a reason unless it is already blocked
+func (repo *userRepository) BlockUser(ctx context.Context, userUUID, reason string, blockedAt time.Time) error {
+	query := `
+		UPDATE
+			users
+		SET
+			is_blocked = TRUE,
+			blocked_at = $2,
+			blocked_reason = $3,
+			updated_at = $2
+		WHERE
+			uuid = $1
+			AND NOT is_blocked`
+
+	result, err := repo.db.ExecContext(ctx, query, userUUID, blockedAt, reason)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error blocking user")
+		return err
+	}
+
+	return expectAffected(result, utils.ErrUserAlreadyBlocked)
+}
+
+// UnblockUser unblocks the user if it is blocked
+func (repo *userRepository) UnblockUser(ctx context.Context, userUUID string, updatedAt time.Time) error {
+	query := `
+		UPDATE
+			users
+		SET
+			is_blocked = FALSE,
+			blocked_at = NULL,
+			blocked_reason = NULL,
+			updated_at = $2
+		WHERE
+			uuid = $1
+			AND is_blocked`
+
+	result, err := repo.db.ExecContext(ctx, query, userUUID, updatedAt)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error unblocking user")
+		return err
+	}
+
+	return expectAffected(result, utils.ErrUserNotBlocked)
+}
+
+// userFilterConditions builds the WHERE clause and its arguments for the filter
+func userFilterConditions(filter reporitory.UserFilter) (string, []any) {
+	var (
+		conditions []string
+		args       []any
+	)
+
+	if filter.Email != "" {
+		args = append(args, "%"+escapeLike(filter.Email)+"%")
+		conditions = append(conditions, fmt.Sprintf("email ILIKE $%d", len(args)))
+	}
+
+	if filter.Name != "" {
+		args = append(args, "%"+escapeLike(filter.Name)+"%")
+		conditions = append(conditions, fmt.Sprintf("name ILIKE $%d", len(args)))
+	}
+
+	if filter.IsBlocked != nil {
+		args = append(args, *filter.IsBlocked)
+		conditions = append(conditions, fmt.Sprintf("is_blocked = $%d", len(args)))
+	}
+
+	if filter.IsDeleted != nil {
+		args = append(args, *filter.IsDeleted)
+		conditions = append(conditions, fmt.Sprintf("is_deleted = $%d", len(args)))
+	}
+
+	if filter.IsEmailVerified != nil {
+		args = append(args, *filter.IsEmailVerified)
+		conditions = append(conditions, fmt.Sprintf("is_email_verified = $%d", len(args)))
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+
+	return "\n\t\tWHERE\n\t\t\t" + strings.Join(conditions, "\n\t\t\tAND "), args
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// scanUsers scans every users row
+func scanUsers(rows *sql.Rows) ([]*entity.User, error) {
+	users := []*entity.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			logger.Base().WithError(err).Error("Error scanning user")
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Base().WithError(err).Error("Error iterating users")
+		return nil, err
+	}
+
+	return users, nil
+}
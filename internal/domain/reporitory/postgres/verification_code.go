@@ -0,0 +1,186 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/edutav/licentia-usoris/infrastructure/logger"
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+	"github.com/edutav/licentia-usoris/internal/domain/reporitory"
+	"github.com/edutav/licentia-usoris/internal/utils"
+)
+
+type verificationCodeRepository struct {
+	db *sql.DB
+}
+
+// NewVerificationCodeRepository creates a new instance of VerificationCodeRepository
+func NewVerificationCodeRepository(db *sql.DB) reporitory.VerificationCodeRepository {
+	return &verificationCodeRepository{
+		db: db,
+	}
+}
+
+// SaveVerificationCode saves the code of the purpose and subject, replacing the previous one
+// and resetting its attempts. The previous code is only replaced when it was issued before
+// issuedBefore, so concurrent requests cannot bypass the cooldown between sends.
+func (repo *verificationCodeRepository) SaveVerificationCode(
+	ctx context.Context, code *entity.VerificationCode, issuedBefore time.Time,
+) error {
+	query := `
+		INSERT INTO verification_codes (
+			purpose,
+			subject,
+			code_hash,
+			expires_at,
+			created_at,
+			send_count,
+			send_window_started_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (purpose, subject) DO UPDATE SET
+			code_hash = EXCLUDED.code_hash,
+			attempts = 0,
+			expires_at = EXCLUDED.expires_at,
+			created_at = EXCLUDED.created_at,
+			consumed_at = NULL,
+			send_count = EXCLUDED.send_count,
+			send_window_started_at = EXCLUDED.send_window_started_at
+		WHERE
+			verification_codes.created_at <= $8
+		RETURNING uuid`
+
+	err := repo.db.QueryRowContext(ctx, query,
+		code.Purpose,
+		code.Subject,
+		code.CodeHash,
+		code.ExpiresAt,
+		code.CreatedAt,
+		code.SendCount,
+		code.SendWindowStartedAt,
+		issuedBefore,
+	).Scan(&code.UUID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return utils.ErrOTPCooldown
+		}
+
+		logger.FromContext(ctx).WithError(err).Error("Error saving verification code")
+		return err
+	}
+
+	return nil
+}
+
+// GetVerificationCode gets the code of the purpose and subject
+func (repo *verificationCodeRepository) GetVerificationCode(ctx context.Context, purpose, subject string) (*entity.VerificationCode, error) {
+	query := `
+		SELECT
+			uuid,
+			purpose,
+			subject,
+			code_hash,
+			attempts,
+			expires_at,
+			created_at,
+			consumed_at,
+			send_count,
+			send_window_started_at
+		FROM
+			verification_codes
+		WHERE
+			purpose = $1
+			AND subject = $2`
+
+	code := &entity.VerificationCode{}
+	var consumedAt sql.NullTime
+
+	err := repo.db.QueryRowContext(ctx, query, purpose, subject).Scan(
+		&code.UUID,
+		&code.Purpose,
+		&code.Subject,
+		&code.CodeHash,
+		&code.Attempts,
+		&code.ExpiresAt,
+		&code.CreatedAt,
+		&consumedAt,
+		&code.SendCount,
+		&code.SendWindowStartedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, utils.ErrInvalidOTP
+		}
+
+		logger.FromContext(ctx).WithError(err).Error("Error getting verification code")
+		return nil, err
+	}
+
+	code.ConsumedAt = consumedAt.Time
+
+	return code, nil
+}
+
+// AddVerificationCodeAttempt counts an attempt on the code. The attempt is counted
+// before the code is compared, so concurrent guesses cannot exceed maxAttempts.
+func (repo *verificationCodeRepository) AddVerificationCodeAttempt(ctx context.Context, codeUUID string, maxAttempts int) error {
+	query := `
+		UPDATE
+			verification_codes
+		SET
+			attempts = attempts + 1
+		WHERE
+			uuid = $1
+			AND attempts < $2`
+
+	result, err := repo.db.ExecContext(ctx, query, codeUUID, maxAttempts)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error adding verification code attempt")
+		return err
+	}
+
+	return expectAffected(result, utils.ErrOTPLocked)
+}
+
+// ConsumeVerificationCode marks the code as consumed. Only an unconsumed code can be
+// consumed, so a concurrent use of the same code loses the race.
+func (repo *verificationCodeRepository) ConsumeVerificationCode(ctx context.Context, codeUUID string) error {
+	query := `
+		UPDATE
+			verification_codes
+		SET
+			consumed_at = NOW()
+		WHERE
+			uuid = $1
+			AND consumed_at IS NULL`
+
+	result, err := repo.db.ExecContext(ctx, query, codeUUID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error consuming verification code")
+		return err
+	}
+
+	return expectAffected(result, utils.ErrInvalidOTP)
+}
+
+// DeleteExpiredVerificationCodes deletes the expired codes. A code is kept until its day window
+// of sends ends, so deleting it does not reset the daily limit.
+func (repo *verificationCodeRepository) DeleteExpiredVerificationCodes(
+	ctx context.Context, expiredBefore, windowStartedBefore time.Time,
+) (int64, error) {
+	query := `
+		DELETE FROM
+			verification_codes
+		WHERE
+			expires_at <= $1
+			AND send_window_started_at <= $2`
+
+	result, err := repo.db.ExecContext(ctx, query, expiredBefore, windowStartedBefore)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error deleting expired verification codes")
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,24 @@
+package reporitory
+
+import (
+	"context"
+
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+)
+
+type RefreshTokenRepository interface {
+	// Create refresh token, starting a new family when the family ID is empty
+	CreateRefreshToken(ctx context.Context, token *entity.RefreshToken) error
+
+	// Get refresh token by the hash of its value
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error)
+
+	// Rotate refresh token, marking the current one as used and creating the next one in the same family
+	RotateRefreshToken(ctx context.Context, current *entity.RefreshToken, next *entity.RefreshToken) error
+
+	// Revoke every refresh token of a family
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+
+	// Revoke every active refresh token of the user
+	RevokeUserRefreshTokens(ctx context.Context, userUUID string) error
+}
@@ -0,0 +1,42 @@
+package reporitory
+
+import (
+	"context"
+
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+)
+
+type RoleRepository interface {
+	// Create role
+	CreateRole(ctx context.Context, role *entity.Role) error
+
+	// List roles
+	ListRoles(ctx context.Context) ([]*entity.Role, error)
+
+	// Get role by UUID
+	GetRoleByUUID(ctx context.Context, roleUUID string) (*entity.Role, error)
+
+	// Update role
+	UpdateRole(ctx context.Context, role *entity.Role) error
+
+	// Delete role and its grants
+	DeleteRole(ctx context.Context, roleUUID string) error
+
+	// List the permissions attached to the role
+	ListRolePermissions(ctx context.Context, roleUUID string) ([]*entity.Permission, error)
+
+	// Attach permission to role
+	AttachPermission(ctx context.Context, roleUUID, permissionUUID string) error
+
+	// Detach permission from role
+	DetachPermission(ctx context.Context, roleUUID, permissionUUID string) error
+
+	// List the roles assigned to the user
+	ListUserRoles(ctx context.Context, userUUID string) ([]*entity.Role, error)
+
+	// Assign role to user
+	AssignRole(ctx context.Context, userUUID, roleUUID string) error
+
+	// Revoke role from user
+	RevokeRole(ctx context.Context, userUUID, roleUUID string) error
+}
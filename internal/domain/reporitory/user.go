@@ -2,10 +2,37 @@ package reporitory
 
 import (
 	"context"
+	"time"
 
 	"github.com/edutav/licentia-usoris/internal/domain/entity"
 )
 
+// UserFilter holds the criteria to list users
+type UserFilter struct {
+	Email           string
+	Name            string
+	IsBlocked       *bool
+	IsDeleted       *bool
+	IsEmailVerified *bool
+	SortBy          string
+	SortDesc        bool
+	Limit           int
+	Offset          int
+}
+
+// UserUpdate holds the profile fields to update, the nil ones are left unchanged
+type UserUpdate struct {
+	Name            *string
+	Email           *string
+	DOB             *time.Time // the zero time clears it
+	PhoneNumber     *string    // an empty number clears it
+	IsEmailVerified *bool
+	UpdatedAt       time.Time
+}
+
+// Columns the users can be sorted by
+var UserSortFields = []string{"name", "email", "created_at", "updated_at", "last_login"}
+
 type UserRepository interface {
 	// Pre-registration new user
 	PreRegisterUser(ctx context.Context, preRegistration *entity.PreRegistration) error
@@ -13,14 +40,54 @@ type UserRepository interface {
 	// Get user by email
 	GetUserByEmail(ctx context.Context, email string) (*entity.User, error)
 
+	// Get user by UUID
+	GetUserByUUID(ctx context.Context, userUUID string) (*entity.User, error)
+
 	// Create user
 	CreateUser(ctx context.Context, user *entity.User) error
 
-	// Get pre-registered user by email and OTP code
-	GetPreRegisteredByEmailAndOTPCode(ctx context.Context, email, otpCode string) (*entity.PreRegistration, error)
+	// Get pre-registered user by email
+	GetPreRegistrationByEmail(ctx context.Context, email string) (*entity.PreRegistration, error)
+
+	// Delete the pending pre-registrations that expired before the given time
+	DeleteExpiredPreRegistrations(ctx context.Context, before time.Time) (int64, error)
 
 	// Update user is verified
 	UpdateUserIsVerified(ctx context.Context, email string) error
 
-	// TODO: Update last login
+	// Get the names of the roles assigned to the user, directly or through its groups
+	GetUserRoles(ctx context.Context, userUUID string) ([]string, error)
+
+	// Get the names of the permissions granted to the user through its roles, none when blocked or deleted
+	GetUserPermissions(ctx context.Context, userUUID string) ([]string, error)
+
+	// Update the user last login
+	UpdateLastLogin(ctx context.Context, userUUID string, lastLogin time.Time) error
+
+	// List the users matching the filter
+	ListUsers(ctx context.Context, filter UserFilter) ([]*entity.User, error)
+
+	// Count the users matching the filter, ignoring its limit and offset
+	CountUsers(ctx context.Context, filter UserFilter) (int, error)
+
+	// Update the given profile fields of the user
+	UpdateUser(ctx context.Context, userUUID string, update UserUpdate) error
+
+	// Soft-delete the user, failing with ErrUserAlreadyDeleted when it is already deleted
+	DeleteUser(ctx context.Context, userUUID string, deletedAt time.Time) error
+
+	// Restore the soft-deleted user, failing with ErrUserNotDeleted when it is not deleted
+	RestoreUser(ctx context.Context, userUUID string, updatedAt time.Time) error
+
+	// Block the user with a reason, failing with ErrUserAlreadyBlocked when it is already blocked
+	BlockUser(ctx context.Context, userUUID, reason string, blockedAt time.Time) error
+
+	// Unblock the user, failing with ErrUserNotBlocked when it is not blocked
+	UnblockUser(ctx context.Context, userUUID string, updatedAt time.Time) error
+
+	// Set the password of the user, keeping the previous one in its password history
+	UpdatePassword(ctx context.Context, userUUID, passwordHash string, updatedAt time.Time) error
+
+	// Get the hashes of the last previous passwords of the user, the most recent first
+	GetPasswordHistory(ctx context.Context, userUUID string, limit int) ([]string, error)
 }
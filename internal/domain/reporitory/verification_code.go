@@ -0,0 +1,25 @@
+package reporitory
+
+import (
+	"context"
+	"time"
+
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+)
+
+type VerificationCodeRepository interface {
+	// Save the code of the purpose and subject, replacing the previous one only when it was issued before issuedBefore
+	SaveVerificationCode(ctx context.Context, code *entity.VerificationCode, issuedBefore time.Time) error
+
+	// Get the code of the purpose and subject
+	GetVerificationCode(ctx context.Context, purpose, subject string) (*entity.VerificationCode, error)
+
+	// Count an attempt on the code, failing when maxAttempts were already made
+	AddVerificationCodeAttempt(ctx context.Context, codeUUID string, maxAttempts int) error
+
+	// Mark the code as consumed, failing when it already was
+	ConsumeVerificationCode(ctx context.Context, codeUUID string) error
+
+	// Delete the codes that expired before expiredBefore and whose day window of sends started before windowStartedBefore
+	DeleteExpiredVerificationCodes(ctx context.Context, expiredBefore, windowStartedBefore time.Time) (int64, error)
+}
@@ -0,0 +1,382 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/edutav/licentia-usoris/infrastructure/server/api"
+	"github.com/edutav/licentia-usoris/internal/presentation/schemas"
+	"github.com/edutav/licentia-usoris/internal/usecases"
+)
+
+// GroupHandler is the handler for group related operations
+type GroupHandler struct {
+	groupUseCase usecases.GroupUseCase
+}
+
+// NewGroupHandler creates a new group handler
+func NewGroupHandler(groupUseCase usecases.GroupUseCase) *GroupHandler {
+	return &GroupHandler{
+		groupUseCase: groupUseCase,
+	}
+}
+
+// Handler for creating a group
+// @Summary Create a group
+// @Description Create a group
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body schemas.GroupInput true "Group details"
+// @Success 201 {object} api.SingleResponse{data=schemas.GroupOutput} "Group created successfully"
+// @Failure 400 {object} api.Problem "Invalid request body"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden"
+// @Failure 409 {object} api.Problem "Group already exists"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /groups [post]
+func (h *GroupHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var input schemas.GroupInput
+	if !decodeJSONBody(w, r, &input) {
+		return
+	}
+
+	input.Name = strings.ToLower(strings.TrimSpace(input.Name))
+	input.Description = strings.TrimSpace(input.Description)
+
+	output, err := h.groupUseCase.CreateGroup(r.Context(), &input)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusCreated, "Group created successfully", output)
+}
+
+// Handler for listing the groups
+// @Summary List groups
+// @Description List every group
+// @Tags groups
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} api.SingleResponse{data=[]schemas.GroupOutput} "Groups found"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /groups [get]
+func (h *GroupHandler) List(w http.ResponseWriter, r *http.Request) {
+	output, err := h.groupUseCase.ListGroups(r.Context())
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Groups found", output)
+}
+
+// Handler for getting a group
+// @Summary Get a group
+// @Description Get a group by UUID
+// @Tags groups
+// @Produce json
+// @Security BearerAuth
+// @Param uuid path string true "Group UUID"
+// @Success 200 {object} api.SingleResponse{data=schemas.GroupOutput} "Group found"
+// @Failure 400 {object} api.Problem "Invalid UUID"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden"
+// @Failure 404 {object} api.Problem "Group not found"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /groups/{uuid} [get]
+func (h *GroupHandler) Get(w http.ResponseWriter, r *http.Request) {
+	groupUUID, ok := pathUUID(w, r, "uuid")
+	if !ok {
+		return
+	}
+
+	output, err := h.groupUseCase.GetGroup(r.Context(), groupUUID)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Group found", output)
+}
+
+// Handler for updating a group
+// @Summary Update a group
+// @Description Update the name and description of a group
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param uuid path string true "Group UUID"
+// @Param input body schemas.GroupInput true "Group details"
+// @Success 200 {object} api.SingleResponse{data=schemas.GroupOutput} "Group updated successfully"
+// @Failure 400 {object} api.Problem "Invalid request body"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden"
+// @Failure 404 {object} api.Problem "Group not found"
+// @Failure 409 {object} api.Problem "Group already exists"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /groups/{uuid} [put]
+func (h *GroupHandler) Update(w http.ResponseWriter, r *http.Request) {
+	groupUUID, ok := pathUUID(w, r, "uuid")
+	if !ok {
+		return
+	}
+
+	var input schemas.GroupInput
+	if !decodeJSONBody(w, r, &input) {
+		return
+	}
+
+	input.Name = strings.ToLower(strings.TrimSpace(input.Name))
+	input.Description = strings.TrimSpace(input.Description)
+
+	output, err := h.groupUseCase.UpdateGroup(r.Context(), groupUUID, &input)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Group updated successfully", output)
+}
+
+// Handler for deleting a group
+// @Summary Delete a group
+// @Description Delete a group, its members and its roles
+// @Tags groups
+// @Produce json
+// @Security BearerAuth
+// @Param uuid path string true "Group UUID"
+// @Success 200 {object} api.SingleResponse "Group deleted successfully"
+// @Failure 400 {object} api.Problem "Invalid UUID"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden"
+// @Failure 404 {object} api.Problem "Group not found"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /groups/{uuid} [delete]
+func (h *GroupHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	groupUUID, ok := pathUUID(w, r, "uuid")
+	if !ok {
+		return
+	}
+
+	err := h.groupUseCase.DeleteGroup(r.Context(), groupUUID)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Group deleted successfully", nil)
+}
+
+// Handler for listing the members of a group
+// @Summary List group members
+// @Description List the members of a group
+// @Tags groups
+// @Produce json
+// @Security BearerAuth
+// @Param uuid path string true "Group UUID"
+// @Success 200 {object} api.SingleResponse{data=[]schemas.GroupMemberOutput} "Group members found"
+// @Failure 400 {object} api.Problem "Invalid UUID"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden"
+// @Failure 404 {object} api.Problem "Group not found"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /groups/{uuid}/members [get]
+func (h *GroupHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	groupUUID, ok := pathUUID(w, r, "uuid")
+	if !ok {
+		return
+	}
+
+	output, err := h.groupUseCase.ListMembers(r.Context(), groupUUID)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Group members found", output)
+}
+
+// Handler for adding a member to a group
+// @Summary Add a member to a group
+// @Description Add a user to a group, granting them the roles of the group. Requires the update:group and update:role permissions, and every permission the roles of the group grant.
+// @Tags groups
+// @Produce json
+// @Security BearerAuth
+// @Param uuid path string true "Group UUID"
+// @Param user_uuid path string true "User UUID"
+// @Success 200 {object} api.SingleResponse "Member added successfully"
+// @Failure 400 {object} api.Problem "Invalid UUID"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden, or the roles of the group grant permissions the caller does not hold"
+// @Failure 404 {object} api.Problem "Group or user not found"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /groups/{uuid}/members/{user_uuid} [put]
+func (h *GroupHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	groupUUID, ok := pathUUID(w, r, "uuid")
+	if !ok {
+		return
+	}
+
+	userUUID, ok := pathUUID(w, r, "user_uuid")
+	if !ok {
+		return
+	}
+
+	principal, ok := currentPrincipal(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.groupUseCase.AddMember(r.Context(), principal, groupUUID, userUUID)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Member added successfully", nil)
+}
+
+// Handler for removing a member from a group
+// @Summary Remove a member from a group
+// @Description Remove a user from a group
+// @Tags groups
+// @Produce json
+// @Security BearerAuth
+// @Param uuid path string true "Group UUID"
+// @Param user_uuid path string true "User UUID"
+// @Success 200 {object} api.SingleResponse "Member removed successfully"
+// @Failure 400 {object} api.Problem "Invalid UUID"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden"
+// @Failure 404 {object} api.Problem "User is not a member of the group"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /groups/{uuid}/members/{user_uuid} [delete]
+func (h *GroupHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	groupUUID, ok := pathUUID(w, r, "uuid")
+	if !ok {
+		return
+	}
+
+	userUUID, ok := pathUUID(w, r, "user_uuid")
+	if !ok {
+		return
+	}
+
+	err := h.groupUseCase.RemoveMember(r.Context(), groupUUID, userUUID)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Member removed successfully", nil)
+}
+
+// Handler for listing the roles of a group
+// @Summary List group roles
+// @Description List the roles assigned to a group and inherited by its members
+// @Tags groups
+// @Produce json
+// @Security BearerAuth
+// @Param uuid path string true "Group UUID"
+// @Success 200 {object} api.SingleResponse{data=[]schemas.RoleOutput} "Group roles found"
+// @Failure 400 {object} api.Problem "Invalid UUID"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden"
+// @Failure 404 {object} api.Problem "Group not found"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /groups/{uuid}/roles [get]
+func (h *GroupHandler) ListRoles(w http.ResponseWriter, r *http.Request) {
+	groupUUID, ok := pathUUID(w, r, "uuid")
+	if !ok {
+		return
+	}
+
+	output, err := h.groupUseCase.ListRoles(r.Context(), groupUUID)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Group roles found", output)
+}
+
+// Handler for assigning a role to a group
+// @Summary Assign a role to a group
+// @Description Assign a role to a group, granting it to every member. Requires the update:group and update:role permissions, and every permission the role grants.
+// @Tags groups
+// @Produce json
+// @Security BearerAuth
+// @Param uuid path string true "Group UUID"
+// @Param role_uuid path string true "Role UUID"
+// @Success 200 {object} api.SingleResponse "Role assigned successfully"
+// @Failure 400 {object} api.Problem "Invalid UUID"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden, or the role grants permissions the caller does not hold"
+// @Failure 404 {object} api.Problem "Group or role not found"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /groups/{uuid}/roles/{role_uuid} [put]
+func (h *GroupHandler) AssignRole(w http.ResponseWriter, r *http.Request) {
+	groupUUID, ok := pathUUID(w, r, "uuid")
+	if !ok {
+		return
+	}
+
+	roleUUID, ok := pathUUID(w, r, "role_uuid")
+	if !ok {
+		return
+	}
+
+	principal, ok := currentPrincipal(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.groupUseCase.AssignRole(r.Context(), principal, groupUUID, roleUUID)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Role assigned successfully", nil)
+}
+
+// Handler for revoking a role from a group
+// @Summary Revoke a role from a group
+// @Description Revoke a role from a group. Requires the update:group and update:role permissions.
+// @Tags groups
+// @Produce json
+// @Security BearerAuth
+// @Param uuid path string true "Group UUID"
+// @Param role_uuid path string true "Role UUID"
+// @Success 200 {object} api.SingleResponse "Role revoked successfully"
+// @Failure 400 {object} api.Problem "Invalid UUID"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden"
+// @Failure 404 {object} api.Problem "Role not assigned to group"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /groups/{uuid}/roles/{role_uuid} [delete]
+func (h *GroupHandler) RevokeRole(w http.ResponseWriter, r *http.Request) {
+	groupUUID, ok := pathUUID(w, r, "uuid")
+	if !ok {
+		return
+	}
+
+	roleUUID, ok := pathUUID(w, r, "role_uuid")
+	if !ok {
+		return
+	}
+
+	err := h.groupUseCase.RevokeRole(r.Context(), groupUUID, roleUUID)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Role revoked successfully", nil)
+}
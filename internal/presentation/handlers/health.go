@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/edutav/licentia-usoris/infrastructure/health"
+	"github.com/edutav/licentia-usoris/infrastructure/server/api"
+)
+
+// HealthHandler is the handler for the liveness and readiness probes.
+// The probes are served outside the API base path, so they are not in the docs.
+type HealthHandler struct {
+	readiness *health.Readiness
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(readiness *health.Readiness) *HealthHandler {
+	return &HealthHandler{
+		readiness: readiness,
+	}
+}
+
+// Live reports the process is running. It checks no dependency, so a failing
+// dependency makes the service unready instead of restarting it.
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	api.SendSingleResponse(w, http.StatusOK, "Alive", map[string]string{
+		"status": health.StatusUp,
+	})
+}
+
+// Ready reports whether the service can serve traffic, with the outcome of every check
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	report := h.readiness.Check(r.Context())
+
+	if report.Status != health.StatusUp {
+		api.SendSingleResponse(w, http.StatusServiceUnavailable, "Not ready", report)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Ready", report)
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/edutav/licentia-usoris/infrastructure/server/api"
+	"github.com/edutav/licentia-usoris/internal/presentation/schemas"
+	"github.com/edutav/licentia-usoris/internal/usecases"
+)
+
+// MFAHandler is the handler for two-factor authentication operations
+type MFAHandler struct {
+	mfaUseCase usecases.MFAUseCase
+}
+
+// NewMFAHandler creates a new MFA handler
+func NewMFAHandler(mfaUseCase usecases.MFAUseCase) *MFAHandler {
+	return &MFAHandler{
+		mfaUseCase: mfaUseCase,
+	}
+}
+
+// Handler for enrolling an authenticator app
+// @Summary Enroll an authenticator app
+// @Description Generate a TOTP secret for the authenticated user and return its otpauth URI and QR code. Two-factor authentication is enabled once the enrollment is confirmed with a first code.
+// @Tags mfa
+// @Produce json
+// @Security BearerAuth
+// @Success 201 {object} api.SingleResponse{data=schemas.TOTPEnrollmentOutput} "Authenticator app enrollment started"
+// @Failure 401 {object} api.Problem "Invalid or revoked token"
+// @Failure 409 {object} api.Problem "Two-factor authentication already enabled"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /user/mfa/totp [post]
+func (h *MFAHandler) Enroll(w http.ResponseWriter, r *http.Request) {
+	principal, ok := currentPrincipal(w, r)
+	if !ok {
+		return
+	}
+
+	output, err := h.mfaUseCase.EnrollTOTP(r.Context(), principal)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusCreated, "Authenticator app enrollment started", output)
+}
+
+// Handler for confirming the enrollment of an authenticator app
+// @Summary Confirm an authenticator app
+// @Description Enable two-factor authentication with a first code of the enrolled authenticator app
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body schemas.MFACodeInput true "Code of the authenticator app"
+// @Success 200 {object} api.SingleResponse "Two-factor authentication enabled"
+// @Failure 400 {object} api.Problem "Invalid code"
+// @Failure 401 {object} api.Problem "Invalid or revoked token"
+// @Failure 404 {object} api.Problem "No pending enrollment"
+// @Failure 409 {object} api.Problem "Two-factor authentication already enabled"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /user/mfa/totp/confirm [post]
+func (h *MFAHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	principal, ok := currentPrincipal(w, r)
+	if !ok {
+		return
+	}
+
+	var input schemas.MFACodeInput
+	if !decodeJSONBody(w, r, &input) {
+		return
+	}
+
+	input.Code = strings.TrimSpace(input.Code)
+
+	err := h.mfaUseCase.ConfirmTOTP(r.Context(), principal, &input)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Two-factor authentication enabled", nil)
+}
+
+// Handler for disabling two-factor authentication
+// @Summary Disable two-factor authentication
+// @Description Disable two-factor authentication with the password and a code of the authenticator app or a recovery code
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body schemas.DisableMFAInput true "Password and code of the authenticator app or recovery code"
+// @Success 200 {object} api.SingleResponse "Two-factor authentication disabled"
+// @Failure 400 {object} api.Problem "Invalid code"
+// @Failure 401 {object} api.Problem "Invalid or revoked token"
+// @Failure 403 {object} api.Problem "Incorrect password"
+// @Failure 409 {object} api.Problem "Two-factor authentication not enabled"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /user/mfa/totp/disable [post]
+func (h *MFAHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	principal, ok := currentPrincipal(w, r)
+	if !ok {
+		return
+	}
+
+	var input schemas.DisableMFAInput
+	if !decodeJSONBody(w, r, &input) {
+		return
+	}
+
+	input.Code = strings.TrimSpace(input.Code)
+
+	err := h.mfaUseCase.DisableTOTP(r.Context(), principal, &input)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Two-factor authentication disabled", nil)
+}
+
+// Handler for completing a login with two-factor authentication
+// @Summary Complete an MFA challenge
+// @Description Exchange the MFA challenge token returned by the login and a code of the authenticator app, or a recovery code, for access and refresh tokens. The challenge token is spent by the first attempt.
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param input body schemas.MFAChallengeInput true "Challenge token and code of the authenticator app or recovery code"
+// @Success 200 {object} api.SingleResponse{data=schemas.TokenOutput} "User logged in successfully"
+// @Failure 400 {object} api.Problem "Invalid code"
+// @Failure 401 {object} api.Problem "Invalid or expired challenge"
+// @Failure 403 {object} api.Problem "User blocked or deleted"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /user/login/mfa [post]
+func (h *MFAHandler) Challenge(w http.ResponseWriter, r *http.Request) {
+	var input schemas.MFAChallengeInput
+	if !decodeJSONBody(w, r, &input) {
+		return
+	}
+
+	input.MFAToken = strings.TrimSpace(input.MFAToken)
+	input.Code = strings.TrimSpace(input.Code)
+
+	output, err := h.mfaUseCase.VerifyChallenge(r.Context(), &input)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "User logged in successfully", output)
+}
+
+// Handler for generating recovery codes
+// @Summary Generate recovery codes
+// @Description Generate ten single-use recovery codes, replacing the previous ones. Each can be used once in place of a code of the authenticator app. The codes are only shown in this response.
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body schemas.RecoveryCodesInput true "Password of the user"
+// @Success 201 {object} api.SingleResponse{data=schemas.RecoveryCodesOutput} "Recovery codes generated"
+// @Failure 401 {object} api.Problem "Invalid or revoked token"
+// @Failure 403 {object} api.Problem "Incorrect password"
+// @Failure 409 {object} api.Problem "Two-factor authentication not enabled"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /user/mfa/recovery-codes [post]
+func (h *MFAHandler) RecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	principal, ok := currentPrincipal(w, r)
+	if !ok {
+		return
+	}
+
+	var input schemas.RecoveryCodesInput
+	if !decodeJSONBody(w, r, &input) {
+		return
+	}
+
+	output, err := h.mfaUseCase.GenerateRecoveryCodes(r.Context(), principal, &input)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusCreated, "Recovery codes generated", output)
+}
+
+// Handler for getting the security profile of the authenticated user
+// @Summary Get the security profile
+// @Description Get whether two-factor authentication is enabled and how many recovery codes are left
+// @Tags mfa
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} api.SingleResponse{data=schemas.SecurityProfileOutput} "Security profile found"
+// @Failure 401 {object} api.Problem "Invalid or revoked token"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /user/me/security [get]
+func (h *MFAHandler) SecurityProfile(w http.ResponseWriter, r *http.Request) {
+	principal, ok := currentPrincipal(w, r)
+	if !ok {
+		return
+	}
+
+	output, err := h.mfaUseCase.GetSecurityProfile(r.Context(), principal)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Security profile found", output)
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/edutav/licentia-usoris/infrastructure/server/api"
+	"github.com/edutav/licentia-usoris/internal/presentation/schemas"
+	"github.com/edutav/licentia-usoris/internal/usecases"
+	"github.com/edutav/licentia-usoris/internal/utils"
+	"github.com/edutav/licentia-usoris/internal/utils/helpers"
+)
+
+// PasswordHandler is the handler for password related operations
+type PasswordHandler struct {
+	passwordUseCase usecases.PasswordUseCase
+}
+
+// NewPasswordHandler creates a new password handler
+func NewPasswordHandler(passwordUseCase usecases.PasswordUseCase) *PasswordHandler {
+	return &PasswordHandler{
+		passwordUseCase: passwordUseCase,
+	}
+}
+
+// Handler for requesting a password reset
+// @Summary Forgot password
+// @Description Email a single-use password reset link. The response is the same whether or not the email is registered.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param input body schemas.ForgotPasswordInput true "User email"
+// @Success 202 {object} api.SingleResponse "Password reset requested"
+// @Failure 400 {object} api.Problem "Invalid request body"
+// @Router /user/password/forgot [post]
+func (h *PasswordHandler) Forgot(w http.ResponseWriter, r *http.Request) {
+	var input schemas.ForgotPasswordInput
+	if !decodeJSONBody(w, r, &input) {
+		return
+	}
+
+	input.Email = strings.ToLower(strings.TrimSpace(input.Email))
+
+	err := helpers.ValidateEmail(input.Email)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	err = h.passwordUseCase.ForgotPassword(r.Context(), &input)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusAccepted, "If the email is registered, a password reset link has been sent", nil)
+}
+
+// Handler for resetting the password
+// @Summary Reset password
+// @Description Set a new password with a password reset token and end every session of the user
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param input body schemas.ResetPasswordInput true "Reset token and new password"
+// @Success 200 {object} api.SingleResponse "Password reset successfully"
+// @Failure 400 {object} api.Problem "Invalid token or password"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /user/password/reset [post]
+func (h *PasswordHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	var input schemas.ResetPasswordInput
+	if !decodeJSONBody(w, r, &input) {
+		return
+	}
+
+	input.Token = strings.TrimSpace(input.Token)
+	if input.Token == "" {
+		api.SendError(w, r, utils.ErrInvalidPasswordResetToken)
+		return
+	}
+
+	err := h.passwordUseCase.ResetPassword(r.Context(), &input)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Password reset successfully", nil)
+}
+
+// Handler for changing the password of the authenticated user
+// @Summary Change password
+// @Description Change the password of the authenticated user. Every session of the user is ended and a new pair of tokens is returned for the caller.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body schemas.ChangePasswordInput true "Current and new password"
+// @Success 200 {object} api.SingleResponse{data=schemas.TokenOutput} "Password changed successfully"
+// @Failure 400 {object} api.Problem "Invalid or reused password"
+// @Failure 401 {object} api.Problem "Invalid or revoked token"
+// @Failure 403 {object} api.Problem "Incorrect current password or user blocked"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /user/password/change [post]
+func (h *PasswordHandler) Change(w http.ResponseWriter, r *http.Request) {
+	principal, ok := currentPrincipal(w, r)
+	if !ok {
+		return
+	}
+
+	var input schemas.ChangePasswordInput
+	if !decodeJSONBody(w, r, &input) {
+		return
+	}
+
+	output, err := h.passwordUseCase.ChangePassword(r.Context(), principal, &input)
+	if err != nil {
+		api.SendError(w, r, api.WithField(err, "new_password"))
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Password changed successfully", output)
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/edutav/licentia-usoris/infrastructure/server/api"
+	"github.com/edutav/licentia-usoris/internal/presentation/schemas"
+	"github.com/edutav/licentia-usoris/internal/usecases"
+)
+
+// PermissionHandler is the handler for permission related operations
+type PermissionHandler struct {
+	permissionUseCase usecases.PermissionUseCase
+}
+
+// NewPermissionHandler creates a new permission handler
+func NewPermissionHandler(permissionUseCase usecases.PermissionUseCase) *PermissionHandler {
+	return &PermissionHandler{
+		permissionUseCase: permissionUseCase,
+	}
+}
+
+// Handler for creating a permission
+// @Summary Create a permission
+// @Description Create a permission in the "verb:resource" format
+// @Tags permissions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body schemas.PermissionInput true "Permission details"
+// @Success 201 {object} api.SingleResponse{data=schemas.PermissionOutput} "Permission created successfully"
+// @Failure 400 {object} api.Problem "Invalid request body"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden"
+// @Failure 409 {object} api.Problem "Permission already exists"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /permissions [post]
+func (h *PermissionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var input schemas.PermissionInput
+	if !decodeJSONBody(w, r, &input) {
+		return
+	}
+
+	input.Name = strings.ToLower(strings.TrimSpace(input.Name))
+	input.Description = strings.TrimSpace(input.Description)
+
+	output, err := h.permissionUseCase.CreatePermission(r.Context(), &input)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusCreated, "Permission created successfully", output)
+}
+
+// Handler for listing the permissions
+// @Summary List permissions
+// @Description List every permission
+// @Tags permissions
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} api.SingleResponse{data=[]schemas.PermissionOutput} "Permissions found"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /permissions [get]
+func (h *PermissionHandler) List(w http.ResponseWriter, r *http.Request) {
+	output, err := h.permissionUseCase.ListPermissions(r.Context())
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Permissions found", output)
+}
+
+// Handler for getting a permission
+// @Summary Get a permission
+// @Description Get a permission by UUID
+// @Tags permissions
+// @Produce json
+// @Security BearerAuth
+// @Param uuid path string true "Permission UUID"
+// @Success 200 {object} api.SingleResponse{data=schemas.PermissionOutput} "Permission found"
+// @Failure 400 {object} api.Problem "Invalid UUID"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden"
+// @Failure 404 {object} api.Problem "Permission not found"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /permissions/{uuid} [get]
+func (h *PermissionHandler) Get(w http.ResponseWriter, r *http.Request) {
+	permissionUUID, ok := pathUUID(w, r, "uuid")
+	if !ok {
+		return
+	}
+
+	output, err := h.permissionUseCase.GetPermission(r.Context(), permissionUUID)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Permission found", output)
+}
+
+// Handler for updating a permission
+// @Summary Update a permission
+// @Description Update the name and description of a permission
+// @Tags permissions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param uuid path string true "Permission UUID"
+// @Param input body schemas.PermissionInput true "Permission details"
+// @Success 200 {object} api.SingleResponse{data=schemas.PermissionOutput} "Permission updated successfully"
+// @Failure 400 {object} api.Problem "Invalid request body"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden"
+// @Failure 404 {object} api.Problem "Permission not found"
+// @Failure 409 {object} api.Problem "Permission already exists"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /permissions/{uuid} [put]
+func (h *PermissionHandler) Update(w http.ResponseWriter, r *http.Request) {
+	permissionUUID, ok := pathUUID(w, r, "uuid")
+	if !ok {
+		return
+	}
+
+	var input schemas.PermissionInput
+	if !decodeJSONBody(w, r, &input) {
+		return
+	}
+
+	input.Name = strings.ToLower(strings.TrimSpace(input.Name))
+	input.Description = strings.TrimSpace(input.Description)
+
+	output, err := h.permissionUseCase.UpdatePermission(r.Context(), permissionUUID, &input)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Permission updated successfully", output)
+}
+
+// Handler for deleting a permission
+// @Summary Delete a permission
+// @Description Delete a permission and detach it from every role
+// @Tags permissions
+// @Produce json
+// @Security BearerAuth
+// @Param uuid path string true "Permission UUID"
+// @Success 200 {object} api.SingleResponse "Permission deleted successfully"
+// @Failure 400 {object} api.Problem "Invalid UUID"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden"
+// @Failure 404 {object} api.Problem "Permission not found"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /permissions/{uuid} [delete]
+func (h *PermissionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	permissionUUID, ok := pathUUID(w, r, "uuid")
+	if !ok {
+		return
+	}
+
+	err := h.permissionUseCase.DeletePermission(r.Context(), permissionUUID)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Permission deleted successfully", nil)
+}
@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/edutav/licentia-usoris/infrastructure/auth"
+	"github.com/edutav/licentia-usoris/infrastructure/server/api"
+	"github.com/edutav/licentia-usoris/internal/utils"
+)
+
+// currentPrincipal gets the authenticated caller of the request, sending a problem response when there is none
+func currentPrincipal(w http.ResponseWriter, r *http.Request) (*auth.Principal, bool) {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		api.SendError(w, r, utils.ErrAuthenticationNeeded)
+		return nil, false
+	}
+
+	return principal, true
+}
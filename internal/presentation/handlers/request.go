@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/edutav/licentia-usoris/infrastructure/server/api"
+	"github.com/edutav/licentia-usoris/internal/utils"
+	"github.com/edutav/licentia-usoris/internal/utils/helpers"
+	"github.com/gorilla/mux"
+)
+
+// decodeJSONBody decodes the JSON body of the request, sending a problem response when it is invalid
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	// Check content type
+	if r.Header.Get("Content-Type") != "application/json" {
+		api.SendError(w, r, utils.ErrInvalidContentType)
+		return false
+	}
+
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err != nil {
+		api.SendError(w, r, fmt.Errorf("%w: %v", utils.ErrInvalidRequestBody, err))
+		return false
+	}
+
+	return true
+}
+
+// pathUUID gets a UUID path variable, sending a problem response when it is invalid
+func pathUUID(w http.ResponseWriter, r *http.Request, name string) (string, bool) {
+	value := mux.Vars(r)[name]
+
+	err := helpers.ValidateUUID(value)
+	if err != nil {
+		api.SendError(w, r, api.WithField(err, name))
+		return "", false
+	}
+
+	return value, true
+}
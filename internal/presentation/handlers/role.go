@@ -0,0 +1,382 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/edutav/licentia-usoris/infrastructure/server/api"
+	"github.com/edutav/licentia-usoris/internal/presentation/schemas"
+	"github.com/edutav/licentia-usoris/internal/usecases"
+)
+
+// RoleHandler is the handler for role related operations
+type RoleHandler struct {
+	roleUseCase usecases.RoleUseCase
+}
+
+// NewRoleHandler creates a new role handler
+func NewRoleHandler(roleUseCase usecases.RoleUseCase) *RoleHandler {
+	return &RoleHandler{
+		roleUseCase: roleUseCase,
+	}
+}
+
+// Handler for creating a role
+// @Summary Create a role
+// @Description Create a role
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body schemas.RoleInput true "Role details"
+// @Success 201 {object} api.SingleResponse{data=schemas.RoleOutput} "Role created successfully"
+// @Failure 400 {object} api.Problem "Invalid request body"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden"
+// @Failure 409 {object} api.Problem "Role already exists"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /roles [post]
+func (h *RoleHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var input schemas.RoleInput
+	if !decodeJSONBody(w, r, &input) {
+		return
+	}
+
+	input.Name = strings.ToLower(strings.TrimSpace(input.Name))
+	input.Description = strings.TrimSpace(input.Description)
+
+	output, err := h.roleUseCase.CreateRole(r.Context(), &input)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusCreated, "Role created successfully", output)
+}
+
+// Handler for listing the roles
+// @Summary List roles
+// @Description List every role
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} api.SingleResponse{data=[]schemas.RoleOutput} "Roles found"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /roles [get]
+func (h *RoleHandler) List(w http.ResponseWriter, r *http.Request) {
+	output, err := h.roleUseCase.ListRoles(r.Context())
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Roles found", output)
+}
+
+// Handler for getting a role
+// @Summary Get a role
+// @Description Get a role by UUID
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Param uuid path string true "Role UUID"
+// @Success 200 {object} api.SingleResponse{data=schemas.RoleOutput} "Role found"
+// @Failure 400 {object} api.Problem "Invalid UUID"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden"
+// @Failure 404 {object} api.Problem "Role not found"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /roles/{uuid} [get]
+func (h *RoleHandler) Get(w http.ResponseWriter, r *http.Request) {
+	roleUUID, ok := pathUUID(w, r, "uuid")
+	if !ok {
+		return
+	}
+
+	output, err := h.roleUseCase.GetRole(r.Context(), roleUUID)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Role found", output)
+}
+
+// Handler for updating a role
+// @Summary Update a role
+// @Description Update the name and description of a role
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param uuid path string true "Role UUID"
+// @Param input body schemas.RoleInput true "Role details"
+// @Success 200 {object} api.SingleResponse{data=schemas.RoleOutput} "Role updated successfully"
+// @Failure 400 {object} api.Problem "Invalid request body"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden"
+// @Failure 404 {object} api.Problem "Role not found"
+// @Failure 409 {object} api.Problem "Role already exists"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /roles/{uuid} [put]
+func (h *RoleHandler) Update(w http.ResponseWriter, r *http.Request) {
+	roleUUID, ok := pathUUID(w, r, "uuid")
+	if !ok {
+		return
+	}
+
+	var input schemas.RoleInput
+	if !decodeJSONBody(w, r, &input) {
+		return
+	}
+
+	input.Name = strings.ToLower(strings.TrimSpace(input.Name))
+	input.Description = strings.TrimSpace(input.Description)
+
+	output, err := h.roleUseCase.UpdateRole(r.Context(), roleUUID, &input)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Role updated successfully", output)
+}
+
+// Handler for deleting a role
+// @Summary Delete a role
+// @Description Delete a role, its permissions and its assignments
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Param uuid path string true "Role UUID"
+// @Success 200 {object} api.SingleResponse "Role deleted successfully"
+// @Failure 400 {object} api.Problem "Invalid UUID"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden"
+// @Failure 404 {object} api.Problem "Role not found"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /roles/{uuid} [delete]
+func (h *RoleHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	roleUUID, ok := pathUUID(w, r, "uuid")
+	if !ok {
+		return
+	}
+
+	err := h.roleUseCase.DeleteRole(r.Context(), roleUUID)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Role deleted successfully", nil)
+}
+
+// Handler for listing the permissions of a role
+// @Summary List role permissions
+// @Description List the permissions attached to a role
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Param uuid path string true "Role UUID"
+// @Success 200 {object} api.SingleResponse{data=[]schemas.PermissionOutput} "Role permissions found"
+// @Failure 400 {object} api.Problem "Invalid UUID"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden"
+// @Failure 404 {object} api.Problem "Role not found"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /roles/{uuid}/permissions [get]
+func (h *RoleHandler) ListPermissions(w http.ResponseWriter, r *http.Request) {
+	roleUUID, ok := pathUUID(w, r, "uuid")
+	if !ok {
+		return
+	}
+
+	output, err := h.roleUseCase.ListRolePermissions(r.Context(), roleUUID)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Role permissions found", output)
+}
+
+// Handler for attaching a permission to a role
+// @Summary Attach a permission to a role
+// @Description Attach a permission to a role. Requires the update:role permission, and the permission being attached.
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Param uuid path string true "Role UUID"
+// @Param permission_uuid path string true "Permission UUID"
+// @Success 200 {object} api.SingleResponse "Permission attached successfully"
+// @Failure 400 {object} api.Problem "Invalid UUID"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden, or the caller does not hold the permission"
+// @Failure 404 {object} api.Problem "Role or permission not found"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /roles/{uuid}/permissions/{permission_uuid} [put]
+func (h *RoleHandler) AttachPermission(w http.ResponseWriter, r *http.Request) {
+	roleUUID, ok := pathUUID(w, r, "uuid")
+	if !ok {
+		return
+	}
+
+	permissionUUID, ok := pathUUID(w, r, "permission_uuid")
+	if !ok {
+		return
+	}
+
+	principal, ok := currentPrincipal(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.roleUseCase.AttachPermission(r.Context(), principal, roleUUID, permissionUUID)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Permission attached successfully", nil)
+}
+
+// Handler for detaching a permission from a role
+// @Summary Detach a permission from a role
+// @Description Detach a permission from a role
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Param uuid path string true "Role UUID"
+// @Param permission_uuid path string true "Permission UUID"
+// @Success 200 {object} api.SingleResponse "Permission detached successfully"
+// @Failure 400 {object} api.Problem "Invalid UUID"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden"
+// @Failure 404 {object} api.Problem "Permission not attached to role"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /roles/{uuid}/permissions/{permission_uuid} [delete]
+func (h *RoleHandler) DetachPermission(w http.ResponseWriter, r *http.Request) {
+	roleUUID, ok := pathUUID(w, r, "uuid")
+	if !ok {
+		return
+	}
+
+	permissionUUID, ok := pathUUID(w, r, "permission_uuid")
+	if !ok {
+		return
+	}
+
+	err := h.roleUseCase.DetachPermission(r.Context(), roleUUID, permissionUUID)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Permission detached successfully", nil)
+}
+
+// Handler for listing the roles of a user
+// @Summary List user roles
+// @Description List the roles assigned to a user
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Param uuid path string true "User UUID"
+// @Success 200 {object} api.SingleResponse{data=[]schemas.RoleOutput} "User roles found"
+// @Failure 400 {object} api.Problem "Invalid UUID"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden"
+// @Failure 404 {object} api.Problem "User not found"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /users/{uuid}/roles [get]
+func (h *RoleHandler) ListUserRoles(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := pathUUID(w, r, "uuid")
+	if !ok {
+		return
+	}
+
+	output, err := h.roleUseCase.ListUserRoles(r.Context(), userUUID)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "User roles found", output)
+}
+
+// Handler for assigning a role to a user
+// @Summary Assign a role to a user
+// @Description Assign a role to a user. Requires the update:user and update:role permissions, and every permission the role grants.
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Param uuid path string true "User UUID"
+// @Param role_uuid path string true "Role UUID"
+// @Success 200 {object} api.SingleResponse "Role assigned successfully"
+// @Failure 400 {object} api.Problem "Invalid UUID"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden, or the role grants permissions the caller does not hold"
+// @Failure 404 {object} api.Problem "User or role not found"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /users/{uuid}/roles/{role_uuid} [put]
+func (h *RoleHandler) AssignRole(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := pathUUID(w, r, "uuid")
+	if !ok {
+		return
+	}
+
+	roleUUID, ok := pathUUID(w, r, "role_uuid")
+	if !ok {
+		return
+	}
+
+	principal, ok := currentPrincipal(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.roleUseCase.AssignRole(r.Context(), principal, userUUID, roleUUID)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Role assigned successfully", nil)
+}
+
+// Handler for revoking a role from a user
+// @Summary Revoke a role from a user
+// @Description Revoke a role from a user. Requires the update:user and update:role permissions.
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Param uuid path string true "User UUID"
+// @Param role_uuid path string true "Role UUID"
+// @Success 200 {object} api.SingleResponse "Role revoked successfully"
+// @Failure 400 {object} api.Problem "Invalid UUID"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden"
+// @Failure 404 {object} api.Problem "Role not assigned to user"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /users/{uuid}/roles/{role_uuid} [delete]
+func (h *RoleHandler) RevokeRole(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := pathUUID(w, r, "uuid")
+	if !ok {
+		return
+	}
+
+	roleUUID, ok := pathUUID(w, r, "role_uuid")
+	if !ok {
+		return
+	}
+
+	err := h.roleUseCase.RevokeRole(r.Context(), userUUID, roleUUID)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Role revoked successfully", nil)
+}
@@ -2,6 +2,9 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strings"
 
@@ -26,29 +29,22 @@ func NewUserHandler(userUseCase usecases.UserUseCase) *UserHandler {
 
 // Handler for pre-registering a new user
 // @Summary Pre-register a new user
-// @Description Pre-register a new user
+// @Description Pre-register a new user and email an OTP code. Pre-registering again replaces a pending pre-registration of the same email, within the limits of the code resends.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param input body schemas.PreRegistrationInput true "User details"
 // @Success 201 {object} api.SingleResponse "User pre-registered successfully"
-// @Failure 400 {object} api.ErrorResponse "Invalid request body"
-// @Failure 404 {object} api.ErrorResponse "User not found"
-// @Failure 409 {object} api.ErrorResponse "Email already exists"
-// @Failure 415 {object} api.ErrorResponse "Invalid content type"
-// @Failure 500 {object} api.ErrorResponse "Internal server error"
-// @Router /users/pre-register [post]
+// @Failure 400 {object} api.Problem "Invalid request body"
+// @Failure 404 {object} api.Problem "User not found"
+// @Failure 409 {object} api.Problem "Email already exists"
+// @Failure 415 {object} api.Problem "Invalid content type"
+// @Failure 429 {object} api.Problem "OTP code requested too often"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /user/pre-register [post]
 func (h *UserHandler) PreRegister(w http.ResponseWriter, r *http.Request) {
-	// Check content type
-	if r.Header.Get("Content-Type") != "application/json" {
-		api.SendErrorResponse(w, http.StatusBadRequest, "Invalid content type", "Content type must be application/json")
-		return
-	}
-
-	var input *schemas.PreRegistrationInput
-	err := json.NewDecoder(r.Body).Decode(&input)
-	if err != nil {
-		api.SendErrorResponse(w, http.StatusBadRequest, "Invalid request body", err.Error())
+	var input schemas.PreRegistrationInput
+	if !decodeJSONBody(w, r, &input) {
 		return
 	}
 
@@ -57,107 +53,72 @@ func (h *UserHandler) PreRegister(w http.ResponseWriter, r *http.Request) {
 	input.PhoneNumber = strings.TrimSpace(input.PhoneNumber)
 	input.DateOfBirth = strings.TrimSpace(input.DateOfBirth)
 
-	// Validate input name
-	err = helpers.ValidateName(input.Name)
+	// Validate the input, reporting every invalid field
+	validations := []error{
+		helpers.ValidateName(input.Name),
+		helpers.ValidateEmail(input.Email),
+		helpers.ValidatePassword(input.Password),
+	}
+	if input.PhoneNumber != "" {
+		validations = append(validations, helpers.ValidatePhoneNumber(input.PhoneNumber))
+	}
+	if input.DateOfBirth != "" {
+		validations = append(validations, helpers.ValidateDOB(input.DateOfBirth))
+	}
+
+	err := errors.Join(validations...)
 	if err != nil {
-		api.SendErrorResponse(w, http.StatusBadRequest, "Invalid name", "Please provide a valid name")
+		api.SendError(w, r, err)
 		return
 	}
 
-	// Validate input email
-	err = helpers.ValidateEmail(input.Email)
+	// Pre-register user
+	err = h.userUseCase.PreRegisterUser(r.Context(), &input)
 	if err != nil {
-		switch err {
-		case utils.ErrMissingEmail:
-			api.SendErrorResponse(w, http.StatusBadRequest, "Missing email", "Please provide an email address")
-		case utils.ErrInvalidEmail:
-			api.SendErrorResponse(w, http.StatusBadRequest, "Invalid email", "Please provide a valid email address")
-		default:
-			api.SendErrorResponse(w, http.StatusInternalServerError, "Internal server error", err.Error())
-		}
-
+		api.SendError(w, r, err)
 		return
 	}
 
-	// Validate input phone number
-	if input.PhoneNumber != "" {
-		err = helpers.ValidatePhoneNumber(input.PhoneNumber)
-		if err != nil {
-			switch err {
-			case utils.ErrMissingPhoneNumber:
-				api.SendErrorResponse(w, http.StatusBadRequest, "Missing phone number", "Please provide a phone number")
-			case utils.ErrInvalidPhoneNumber:
-				api.SendErrorResponse(w, http.StatusBadRequest, "Invalid phone number", "Please provide a valid phone number")
-			default:
-				api.SendErrorResponse(w, http.StatusInternalServerError, "Internal server error", err.Error())
-			}
+	api.SendSingleResponse(w, http.StatusCreated, "User pre-registered successfully", nil)
+}
 
-			return
-		}
+// Handler for resending the OTP code of a pre-registration
+// @Summary Resend the OTP code
+// @Description Email a new OTP code for a pending pre-registration, replacing the previous one. A code can only be resent after a cooldown, and a limited number of times a day.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param input body schemas.ResendOTPInput true "User email"
+// @Success 202 {object} api.SingleResponse "OTP code sent"
+// @Failure 400 {object} api.Problem "Invalid request body"
+// @Failure 404 {object} api.Problem "Pre-registration not found"
+// @Failure 409 {object} api.Problem "Email already verified"
+// @Failure 410 {object} api.Problem "Pre-registration expired"
+// @Failure 429 {object} api.Problem "OTP code requested too often"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /user/pre-register/resend [post]
+func (h *UserHandler) ResendOTP(w http.ResponseWriter, r *http.Request) {
+	var input schemas.ResendOTPInput
+	if !decodeJSONBody(w, r, &input) {
+		return
 	}
 
-	// Validate input date of birth
-	if input.DateOfBirth != "" {
-		err = helpers.ValidateDOB(input.DateOfBirth)
-		if err != nil {
-			api.SendErrorResponse(w, http.StatusBadRequest, "Invalid date of birth", "Please provide a valid date of birth")
-			return
-		}
-	}
+	input.Email = strings.ToLower(strings.TrimSpace(input.Email))
 
-	// Validate input password
-	err = helpers.ValidatePassword(input.Password)
+	// Validate input email
+	err := helpers.ValidateEmail(input.Email)
 	if err != nil {
-		api.SendErrorResponse(w, http.StatusBadRequest, "Missing password", "Please provide a password")
+		api.SendError(w, r, err)
 		return
 	}
 
-	// Pre-register user
-	err = h.userUseCase.PreRegisterUser(r.Context(), input)
+	err = h.userUseCase.ResendOTPCode(r.Context(), input.Email)
 	if err != nil {
-		switch err {
-		case utils.ErrUserNotFound:
-			api.SendErrorResponse(w, http.StatusNotFound, "User not found", "User not found")
-		case utils.ErrUserNotFound:
-			api.SendErrorResponse(w, http.StatusNotFound, "User not found", "User not found")
-		case utils.ErrPasswordTooShort:
-			api.SendErrorResponse(w, http.StatusBadRequest, "Password too short", "Password must be at least 8 characters")
-		case utils.ErrPasswordTooLong:
-			api.SendErrorResponse(w, http.StatusBadRequest, "Password too long", "Password must be at most 72 characters")
-		case utils.ErrPasswordSecurity:
-			api.SendErrorResponse(w, http.StatusBadRequest, "Password not safe", "Password must contain at least one uppercase letter, one lowercase letter, one number, and one special character")
-		case utils.ErrHashingPassword:
-			api.SendErrorResponse(w, http.StatusInternalServerError, "Error hashing password", "Error hashing password")
-		case utils.ErrUserNameTooShort:
-			api.SendErrorResponse(w, http.StatusBadRequest, "User name too short", "User name must be at least 3 characters")
-		case utils.ErrUserNameTooLong:
-			api.SendErrorResponse(w, http.StatusBadRequest, "User name too long", "User name must be at most 200 characters")
-		case utils.ErrUserNameWithNumericVals:
-			api.SendErrorResponse(w, http.StatusBadRequest, "User name with numeric characters", "User name must not contain numeric characters")
-		case utils.ErrDOBFormat:
-			api.SendErrorResponse(w, http.StatusBadRequest, "Invalid date of birth format", "Invalid date of birth format")
-
-		case utils.ErrDuplicateEmail:
-			api.SendErrorResponse(w, http.StatusConflict, "Email already exists", "Email already exists")
-		case utils.ErrGenerateOTP:
-			api.SendErrorResponse(w, http.StatusInternalServerError, "Error generating OTP", "Error generating OTP")
-		case utils.ErrCreateVericationEntry:
-			api.SendErrorResponse(
-				w,
-				http.StatusInternalServerError,
-				"Error creating verification entry",
-				"Error creating verification entry",
-			)
-		case utils.ErrSMTPServerIssue:
-			api.SendErrorResponse(w, http.StatusInternalServerError, "SMTP server issue", "SMTP server issue")
-		default:
-			api.SendErrorResponse(w, http.StatusInternalServerError, "Internal server error", err.Error())
-		}
-
+		api.SendError(w, r, err)
 		return
 	}
 
-	api.SendSingleResponse(w, http.StatusCreated, "User pre-registered successfully", nil)
+	api.SendSingleResponse(w, http.StatusAccepted, "OTP code sent", nil)
 }
 
 // Handler for registering a new user
@@ -168,65 +129,187 @@ func (h *UserHandler) PreRegister(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param input body schemas.VerifyOTPInput true "User details"
 // @Success 201 {object} api.SingleResponse "User registered successfully"
-// @Failure 400 {object} api.ErrorResponse "Invalid request body"
-// @Failure 404 {object} api.ErrorResponse "User not found"
-// @Failure 409 {object} api.ErrorResponse "Email already exists"
-// @Failure 415 {object} api.ErrorResponse "Invalid content type"
-// @Failure 500 {object} api.ErrorResponse "Internal server error"
-// @Router /users/register [post]
+// @Failure 400 {object} api.Problem "Invalid request body"
+// @Failure 404 {object} api.Problem "User not found"
+// @Failure 409 {object} api.Problem "Email already verified or exists"
+// @Failure 410 {object} api.Problem "OTP code expired"
+// @Failure 415 {object} api.Problem "Invalid content type"
+// @Failure 429 {object} api.Problem "Too many invalid attempts"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /user/register [post]
 func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
-	// Check content type
-	if r.Header.Get("Content-Type") != "application/json" {
-		api.SendErrorResponse(w, http.StatusBadRequest, "Invalid content type", "Content type must be application/json")
+	var input schemas.VerifyOTPInput
+	if !decodeJSONBody(w, r, &input) {
 		return
 	}
 
-	var input *schemas.VerifyOTPInput
-	err := json.NewDecoder(r.Body).Decode(&input)
+	input.Email = strings.TrimSpace(input.Email)
+
+	// Validate input email
+	err := helpers.ValidateEmail(input.Email)
 	if err != nil {
-		api.SendErrorResponse(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		api.SendError(w, r, err)
 		return
 	}
 
-	input.Email = strings.TrimSpace(input.Email)
+	// Verify OTP code
+	err = h.userUseCase.VerifyOTPCode(r.Context(), input.Email, input.OTP)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
 
-	// Validate input email
-	err = helpers.ValidateEmail(input.Email)
+	api.SendSingleResponse(w, http.StatusCreated, "User created successfully", nil)
+}
+
+// Handler for logging in a user
+// @Summary Login a user
+// @Description Authenticate a user with email and password and issue access and refresh tokens. When two-factor authentication is enabled, an MFA challenge token is returned instead, to be completed at /user/login/mfa.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param input body schemas.LoginInput true "User credentials"
+// @Success 200 {object} api.SingleResponse{data=schemas.LoginOutput} "User logged in successfully"
+// @Failure 400 {object} api.Problem "Invalid request body"
+// @Failure 401 {object} api.Problem "Invalid email or password"
+// @Failure 403 {object} api.Problem "User blocked, deleted or email not verified"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /user/login [post]
+func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var input schemas.LoginInput
+	if !decodeJSONBody(w, r, &input) {
+		return
+	}
+
+	input.Email = strings.ToLower(strings.TrimSpace(input.Email))
+
+	// Validate the input, reporting every invalid field
+	err := errors.Join(
+		helpers.ValidateEmail(input.Email),
+		helpers.ValidatePassword(input.Password),
+	)
 	if err != nil {
-		switch err {
-		case utils.ErrMissingEmail:
-			api.SendErrorResponse(w, http.StatusBadRequest, "Missing email", "Please provide an email address")
-		case utils.ErrInvalidEmail:
-			api.SendErrorResponse(w, http.StatusBadRequest, "Invalid email", "Please provide a valid email address")
-		default:
-			api.SendErrorResponse(w, http.StatusInternalServerError, "Internal server error", err.Error())
-		}
+		api.SendError(w, r, err)
+		return
+	}
 
+	// Login user
+	output, err := h.userUseCase.Login(r.Context(), &input)
+	if err != nil {
+		api.SendError(w, r, err)
 		return
 	}
 
-	// Verify OTP code
-	err = h.userUseCase.VerifyOTPCode(r.Context(), input.Email, input.OTP)
+	if output.MFARequired {
+		api.SendSingleResponse(w, http.StatusOK, "Two-factor authentication required", output)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "User logged in successfully", output)
+}
+
+// Handler for refreshing the tokens of a user
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new pair of access and refresh tokens. The presented refresh token is rotated and cannot be used again.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param input body schemas.RefreshTokenInput true "Refresh token"
+// @Success 200 {object} api.SingleResponse{data=schemas.TokenOutput} "Tokens refreshed successfully"
+// @Failure 400 {object} api.Problem "Invalid request body"
+// @Failure 401 {object} api.Problem "Invalid or reused refresh token"
+// @Failure 403 {object} api.Problem "User blocked or deleted"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /user/token/refresh [post]
+func (h *UserHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	var input schemas.RefreshTokenInput
+	if !decodeJSONBody(w, r, &input) {
+		return
+	}
+
+	input.RefreshToken = strings.TrimSpace(input.RefreshToken)
+	if input.RefreshToken == "" {
+		api.SendError(w, r, utils.ErrMissingRefreshToken)
+		return
+	}
+
+	// Rotate refresh token
+	output, err := h.userUseCase.RefreshToken(r.Context(), input.RefreshToken)
 	if err != nil {
-		switch err {
-		case utils.ErrPreRegistredUserNotFound:
-			api.SendErrorResponse(w, http.StatusNotFound, "User not found", "User not found")
-		case utils.ErrOTPExpired:
-			api.SendErrorResponse(w, http.StatusBadRequest, "OTP code expired", "OTP code expired")
-		case utils.ErrOTPAlreadyVerified:
-			api.SendErrorResponse(w, http.StatusBadRequest, "OTP code already verified", "OTP code already verified")
-		case utils.ErrInvalidOTP:
-			api.SendErrorResponse(w, http.StatusBadRequest, "Invalid OTP code", "Invalid OTP code")
-		case utils.ErrUserNotFound:
-			api.SendErrorResponse(w, http.StatusNotFound, "User not found", "User not found")
-		case utils.ErrDuplicateEmail:
-			api.SendErrorResponse(w, http.StatusConflict, "Email already exists", "Email already exists")
-		default:
-			api.SendErrorResponse(w, http.StatusInternalServerError, "Internal server error", err.Error())
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "Tokens refreshed successfully", output)
+}
+
+// Handler for logging out a user
+// @Summary Logout a user
+// @Description Revoke the presented access token and, when given, the refresh token family of the session
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body schemas.LogoutInput false "Refresh token of the session"
+// @Success 200 {object} api.SingleResponse "User logged out successfully"
+// @Failure 400 {object} api.Problem "Invalid request body"
+// @Failure 401 {object} api.Problem "Invalid or revoked token"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /user/logout [post]
+func (h *UserHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	principal, ok := currentPrincipal(w, r)
+	if !ok {
+		return
+	}
+
+	// The body is optional
+	var input schemas.LogoutInput
+	if r.ContentLength != 0 {
+		// Check content type
+		if r.Header.Get("Content-Type") != "application/json" {
+			api.SendError(w, r, utils.ErrInvalidContentType)
+			return
+		}
+
+		err := json.NewDecoder(r.Body).Decode(&input)
+		if err != nil && err != io.EOF {
+			api.SendError(w, r, fmt.Errorf("%w: %v", utils.ErrInvalidRequestBody, err))
+			return
 		}
+	}
 
+	// Logout user
+	err := h.userUseCase.Logout(r.Context(), principal, strings.TrimSpace(input.RefreshToken))
+	if err != nil {
+		api.SendError(w, r, err)
 		return
 	}
 
-	api.SendSingleResponse(w, http.StatusCreated, "User created successfully", nil)
+	api.SendSingleResponse(w, http.StatusOK, "User logged out successfully", nil)
+}
+
+// Handler for getting the profile of the authenticated user
+// @Summary Get the authenticated user
+// @Description Get the profile of the authenticated user
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} api.SingleResponse{data=schemas.UserOutput} "User found"
+// @Failure 401 {object} api.Problem "Invalid or revoked token"
+// @Failure 404 {object} api.Problem "User not found"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /user/me [get]
+func (h *UserHandler) Me(w http.ResponseWriter, r *http.Request) {
+	principal, ok := currentPrincipal(w, r)
+	if !ok {
+		return
+	}
+
+	output, err := h.userUseCase.GetProfile(r.Context(), principal.UserUUID)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "User found", output)
 }
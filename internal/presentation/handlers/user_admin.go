@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/edutav/licentia-usoris/infrastructure/server/api"
+	"github.com/edutav/licentia-usoris/internal/presentation/schemas"
+	"github.com/edutav/licentia-usoris/internal/usecases"
+	"github.com/edutav/licentia-usoris/internal/utils"
+)
+
+// UserAdminHandler is the handler for the administration of users
+type UserAdminHandler struct {
+	userAdminUseCase usecases.UserAdminUseCase
+}
+
+// NewUserAdminHandler creates a new user administration handler
+func NewUserAdminHandler(userAdminUseCase usecases.UserAdminUseCase) *UserAdminHandler {
+	return &UserAdminHandler{
+		userAdminUseCase: userAdminUseCase,
+	}
+}
+
+// Handler for listing the users
+// @Summary List users
+// @Description List the users with filters, sorting and pagination
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param email query string false "Filter by part of the email"
+// @Param name query string false "Filter by part of the name"
+// @Param blocked query bool false "Filter by blocked status"
+// @Param deleted query bool false "Filter by deleted status"
+// @Param verified query bool false "Filter by email verified status"
+// @Param sort query string false "Sort field" Enums(name, email, created_at, updated_at, last_login)
+// @Param order query string false "Sort order" Enums(asc, desc)
+// @Param page query int false "Page number, skipping at most 10000 users" default(1)
+// @Param limit query int false "Page size" default(10) maximum(100)
+// @Success 200 {object} api.ListResponse{data=[]schemas.UserOutput} "Users found"
+// @Failure 400 {object} api.Problem "Invalid query parameter"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /users [get]
+func (h *UserAdminHandler) List(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	pagination := api.GetPaginationParams(r)
+
+	input := &schemas.UserFilterInput{
+		Email:    strings.ToLower(strings.TrimSpace(query.Get("email"))),
+		Name:     strings.TrimSpace(query.Get("name")),
+		Sort:     query.Get("sort"),
+		Order:    strings.ToLower(query.Get("order")),
+		Page:     pagination.Page,
+		PageSize: pagination.PageSize,
+	}
+
+	for name, target := range map[string]**bool{
+		"blocked":  &input.IsBlocked,
+		"deleted":  &input.IsDeleted,
+		"verified": &input.IsEmailVerified,
+	} {
+		if !query.Has(name) {
+			continue
+		}
+
+		value, err := strconv.ParseBool(query.Get(name))
+		if err != nil {
+			api.SendError(w, r, api.WithField(utils.ErrInvalidBoolean, name))
+			return
+		}
+		*target = &value
+	}
+
+	output, total, err := h.userAdminUseCase.ListUsers(r.Context(), input)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	data := make([]interface{}, 0, len(output))
+	for _, user := range output {
+		data = append(data, user)
+	}
+
+	api.SendPaginatedResponse(w, http.StatusOK, data, total, pagination.Page, pagination.PageSize, listBaseURL(r))
+}
+
+// Handler for getting a user
+// @Summary Get a user
+// @Description Get a user and its roles by UUID
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param uuid path string true "User UUID"
+// @Success 200 {object} api.SingleResponse{data=schemas.UserOutput} "User found"
+// @Failure 400 {object} api.Problem "Invalid UUID"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden"
+// @Failure 404 {object} api.Problem "User not found"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /users/{uuid} [get]
+func (h *UserAdminHandler) Get(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := pathUUID(w, r, "uuid")
+	if !ok {
+		return
+	}
+
+	output, err := h.userAdminUseCase.GetUser(r.Context(), userUUID)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "User found", output)
+}
+
+// Handler for updating a user
+// @Summary Update a user
+// @Description Update the given fields of a user. Empty date of birth or phone number clear them. A new email is unverified unless is_email_verified is given, and revokes the tokens of the user. Requires every permission the user holds.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param uuid path string true "User UUID"
+// @Param input body schemas.UpdateUserInput true "Fields to update"
+// @Success 200 {object} api.SingleResponse{data=schemas.UserOutput} "User updated successfully"
+// @Failure 400 {object} api.Problem "Invalid request body"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden, or the user holds permissions the caller does not hold"
+// @Failure 404 {object} api.Problem "User not found"
+// @Failure 409 {object} api.Problem "Email already exists"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /users/{uuid} [patch]
+func (h *UserAdminHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := pathUUID(w, r, "uuid")
+	if !ok {
+		return
+	}
+
+	var input schemas.UpdateUserInput
+	if !decodeJSONBody(w, r, &input) {
+		return
+	}
+
+	if input.Name != nil {
+		*input.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Email != nil {
+		*input.Email = strings.ToLower(strings.TrimSpace(*input.Email))
+	}
+	if input.DateOfBirth != nil {
+		*input.DateOfBirth = strings.TrimSpace(*input.DateOfBirth)
+	}
+	if input.PhoneNumber != nil {
+		*input.PhoneNumber = strings.TrimSpace(*input.PhoneNumber)
+	}
+
+	principal, ok := currentPrincipal(w, r)
+	if !ok {
+		return
+	}
+
+	output, err := h.userAdminUseCase.UpdateUser(r.Context(), principal, userUUID, &input)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "User updated successfully", output)
+}
+
+// Handler for deleting a user
+// @Summary Delete a user
+// @Description Soft-delete a user, keeping its history, and revoke its tokens. Requires every permission the user holds.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param uuid path string true "User UUID"
+// @Success 200 {object} api.SingleResponse "User deleted successfully"
+// @Failure 400 {object} api.Problem "Invalid UUID"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden, or the user holds permissions the caller does not hold"
+// @Failure 404 {object} api.Problem "User not found"
+// @Failure 409 {object} api.Problem "User already deleted"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /users/{uuid} [delete]
+func (h *UserAdminHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := pathUUID(w, r, "uuid")
+	if !ok {
+		return
+	}
+
+	principal, ok := currentPrincipal(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.userAdminUseCase.DeleteUser(r.Context(), principal, userUUID)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "User deleted successfully", nil)
+}
+
+// Handler for restoring a user
+// @Summary Restore a user
+// @Description Restore a soft-deleted user. Requires every permission the user holds.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param uuid path string true "User UUID"
+// @Success 200 {object} api.SingleResponse "User restored successfully"
+// @Failure 400 {object} api.Problem "Invalid UUID"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden, or the user holds permissions the caller does not hold"
+// @Failure 404 {object} api.Problem "User not found"
+// @Failure 409 {object} api.Problem "User is not deleted"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /users/{uuid}/restore [post]
+func (h *UserAdminHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := pathUUID(w, r, "uuid")
+	if !ok {
+		return
+	}
+
+	principal, ok := currentPrincipal(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.userAdminUseCase.RestoreUser(r.Context(), principal, userUUID)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "User restored successfully", nil)
+}
+
+// Handler for blocking a user
+// @Summary Block a user
+// @Description Block a user with a reason and revoke its tokens. Requires every permission the user holds.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param uuid path string true "User UUID"
+// @Param input body schemas.BlockUserInput true "Block reason"
+// @Success 200 {object} api.SingleResponse "User blocked successfully"
+// @Failure 400 {object} api.Problem "Invalid request body"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden, or the user holds permissions the caller does not hold"
+// @Failure 404 {object} api.Problem "User not found"
+// @Failure 409 {object} api.Problem "User already blocked"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /users/{uuid}/block [post]
+func (h *UserAdminHandler) Block(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := pathUUID(w, r, "uuid")
+	if !ok {
+		return
+	}
+
+	var input schemas.BlockUserInput
+	if !decodeJSONBody(w, r, &input) {
+		return
+	}
+
+	input.Reason = strings.TrimSpace(input.Reason)
+
+	principal, ok := currentPrincipal(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.userAdminUseCase.BlockUser(r.Context(), principal, userUUID, &input)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "User blocked successfully", nil)
+}
+
+// Handler for unblocking a user
+// @Summary Unblock a user
+// @Description Unblock a user. Requires every permission the user holds.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param uuid path string true "User UUID"
+// @Success 200 {object} api.SingleResponse "User unblocked successfully"
+// @Failure 400 {object} api.Problem "Invalid UUID"
+// @Failure 401 {object} api.Problem "Unauthorized"
+// @Failure 403 {object} api.Problem "Forbidden, or the user holds permissions the caller does not hold"
+// @Failure 404 {object} api.Problem "User not found"
+// @Failure 409 {object} api.Problem "User is not blocked"
+// @Failure 500 {object} api.Problem "Internal server error"
+// @Router /users/{uuid}/unblock [post]
+func (h *UserAdminHandler) Unblock(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := pathUUID(w, r, "uuid")
+	if !ok {
+		return
+	}
+
+	principal, ok := currentPrincipal(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.userAdminUseCase.UnblockUser(r.Context(), principal, userUUID)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	api.SendSingleResponse(w, http.StatusOK, "User unblocked successfully", nil)
+}
+
+// listBaseURL builds the base URL of the pagination links, keeping the filters of the request
+func listBaseURL(r *http.Request) string {
+	query := url.Values{}
+	for key, values := range r.URL.Query() {
+		if key == "page" || key == "limit" {
+			continue
+		}
+		query[key] = values
+	}
+
+	if len(query) == 0 {
+		return r.URL.Path
+	}
+
+	return r.URL.Path + "?" + query.Encode()
+}
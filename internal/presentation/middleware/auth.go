@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/edutav/licentia-usoris/infrastructure/auth"
+	"github.com/edutav/licentia-usoris/infrastructure/server/api"
+	"github.com/edutav/licentia-usoris/internal/utils"
+	"github.com/gorilla/mux"
+)
+
+// Authenticate validates the bearer token of the request, rejects revoked tokens and
+// injects the authenticated principal into the request context
+func Authenticate(tokenService auth.TokenService, blacklist auth.TokenBlacklist) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := auth.BearerToken(r)
+			if !ok {
+				sendUnauthorized(w, r, utils.ErrMissingBearerToken)
+				return
+			}
+
+			claims, err := tokenService.ParseAccessToken(token)
+			if err != nil {
+				if err != utils.ErrTokenExpired {
+					err = utils.ErrInvalidToken
+				}
+				sendUnauthorized(w, r, err)
+				return
+			}
+
+			revoked, err := blacklist.IsBlacklisted(r.Context(), claims.ID)
+			if err == nil && !revoked {
+				revoked, err = blacklist.IsUserRevoked(r.Context(), claims.Subject, claims.IssuedAt.Time)
+			}
+
+			if err != nil {
+				api.SendError(w, r, err)
+				return
+			}
+
+			if revoked {
+				sendUnauthorized(w, r, utils.ErrTokenRevoked)
+				return
+			}
+
+			ctx := auth.WithPrincipal(r.Context(), auth.NewPrincipal(claims))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// sendUnauthorized sends the problem of the error asking for a bearer token
+func sendUnauthorized(w http.ResponseWriter, r *http.Request, err error) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	api.SendError(w, r, err)
+}
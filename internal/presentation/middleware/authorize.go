@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/edutav/licentia-usoris/infrastructure/auth"
+	"github.com/edutav/licentia-usoris/infrastructure/logger"
+	"github.com/edutav/licentia-usoris/infrastructure/server/api"
+	"github.com/edutav/licentia-usoris/internal/utils"
+	"github.com/gorilla/mux"
+)
+
+// Authorization struct
+type Authorization struct {
+	authorizer *auth.Authorizer
+}
+
+// NewAuthorization creates the authorization middlewares
+func NewAuthorization(authorizer *auth.Authorizer) *Authorization {
+	return &Authorization{
+		authorizer: authorizer,
+	}
+}
+
+// RequirePermission rejects with 403 the callers that lack any of the permissions.
+// It must run after Authenticate.
+func (a *Authorization) RequirePermission(permissions ...string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := auth.PrincipalFromContext(r.Context())
+			if !ok {
+				sendUnauthorized(w, r, utils.ErrAuthenticationNeeded)
+				return
+			}
+
+			err := a.authorizer.Authorize(r.Context(), principal, permissions...)
+			if err != nil {
+				api.SendError(w, r, err)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// PermissionRouter registers the routes of a subrouter together with the permissions they require.
+// Its routes are denied by default: a route added to the subrouter without declaring its
+// permissions is rejected with 403, so it cannot be left open by mistake.
+type PermissionRouter struct {
+	router        *mux.Router
+	authorization *Authorization
+	permissions   map[*mux.Route][]string
+}
+
+// Router wraps a subrouter of authenticated routes. It must run after Authenticate.
+func (a *Authorization) Router(router *mux.Router) *PermissionRouter {
+	p := &PermissionRouter{
+		router:        router,
+		authorization: a,
+		permissions:   map[*mux.Route][]string{},
+	}
+	router.Use(p.authorize)
+	return p
+}
+
+// Handle registers the handler on the path, requiring every one of the permissions.
+// It panics when no permission is given.
+func (p *PermissionRouter) Handle(path string, handler http.HandlerFunc, permissions ...string) *mux.Route {
+	if len(permissions) == 0 {
+		panic("middleware: route " + path + " declares no permission")
+	}
+
+	route := p.router.Handle(path, handler)
+	p.permissions[route] = permissions
+	return route
+}
+
+// authorize requires the permissions declared by the matched route
+func (p *PermissionRouter) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		permissions, ok := p.permissions[mux.CurrentRoute(r)]
+		if !ok {
+			logger.FromContext(r.Context()).WithField("path", r.URL.Path).Error("Route declares no permission")
+			api.SendError(w, r, utils.ErrPermissionDenied)
+			return
+		}
+
+		p.authorization.RequirePermission(permissions...)(next).ServeHTTP(w, r)
+	})
+}
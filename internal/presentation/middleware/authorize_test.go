@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/edutav/licentia-usoris/infrastructure/auth"
+	"github.com/gorilla/mux"
+)
+
+// fakeResolver grants the same permissions to every user
+type fakeResolver struct {
+	permissions []string
+}
+
+func (r *fakeResolver) GetUserPermissions(ctx context.Context, userUUID string) ([]string, error) {
+	return r.permissions, nil
+}
+
+func TestPermissionRouter(t *testing.T) {
+	tests := []struct {
+		name          string
+		path          string
+		authenticated bool
+		permissions   []string
+		wantStatus    int
+	}{
+		{"granted permission", "/admin/read", true, []string{"read:thing"}, http.StatusOK},
+		{"missing permission", "/admin/read", true, []string{"update:thing"}, http.StatusForbidden},
+		{"every permission required", "/admin/write", true, []string{"update:thing"}, http.StatusForbidden},
+		{"every permission granted", "/admin/write", true, []string{"update:thing", "update:role"}, http.StatusOK},
+		{"undeclared route is denied", "/admin/undeclared", true, []string{"read:thing"}, http.StatusForbidden},
+		{"unauthenticated caller", "/admin/read", false, []string{"read:thing"}, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+
+			router := mux.NewRouter()
+			admin := router.PathPrefix("/admin").Subrouter()
+			admin.Use(func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if tt.authenticated {
+						r = r.WithContext(auth.WithPrincipal(r.Context(), &auth.Principal{UserUUID: "user-1"}))
+					}
+					next.ServeHTTP(w, r)
+				})
+			})
+
+			authorization := NewAuthorization(auth.NewAuthorizer(&fakeResolver{permissions: tt.permissions}))
+			routes := authorization.Router(admin)
+			routes.Handle("/read", ok, "read:thing").Methods(http.MethodGet)
+			routes.Handle("/write", ok, "update:thing", "update:role").Methods(http.MethodGet)
+			admin.HandleFunc("/undeclared", ok).Methods(http.MethodGet)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestPermissionRouterHandleWithoutPermission(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Handle did not panic on a route without permission")
+		}
+	}()
+
+	authorization := NewAuthorization(auth.NewAuthorizer(&fakeResolver{}))
+	authorization.Router(mux.NewRouter()).Handle("/open", func(w http.ResponseWriter, r *http.Request) {})
+}
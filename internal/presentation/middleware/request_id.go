@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+
+	"github.com/edutav/licentia-usoris/infrastructure/logger"
+)
+
+// Header carrying the ID of the request
+const RequestIDHeader = "X-Request-ID"
+
+// IDs accepted from the caller, anything else is replaced
+var requestIDRegex = regexp.MustCompile(`^[A-Za-z0-9._\-]{1,128}$`)
+
+// RequestID propagates the X-Request-ID of the caller, or generates one, returns it in the
+// response and puts a logger carrying it into the request context
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if !requestIDRegex.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, requestID)
+
+		entry := logger.FromContext(r.Context()).WithField("request_id", requestID)
+		next.ServeHTTP(w, r.WithContext(logger.WithContext(r.Context(), entry)))
+	})
+}
+
+// newRequestID generates a random request ID
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
@@ -1,33 +1,73 @@
 package routes
 
 import (
-	"log"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/edutav/licentia-usoris/infrastructure/logger"
+	"github.com/edutav/licentia-usoris/infrastructure/metrics"
+	"github.com/edutav/licentia-usoris/infrastructure/server/api"
+	"github.com/edutav/licentia-usoris/infrastructure/tracing"
 	"github.com/edutav/licentia-usoris/internal/presentation/handlers"
+	"github.com/edutav/licentia-usoris/internal/presentation/middleware"
+	"github.com/edutav/licentia-usoris/internal/utils"
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 	httpSwagger "github.com/swaggo/http-swagger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// statusRecorder struct to record the status of the response
+// statusRecorder struct to record the status and size of the response
 type statusRecorder struct {
 	http.ResponseWriter
-	statusCode int
+	statusCode  int
+	bytes       int
+	wroteHeader bool
 }
 
-// loggingMiddleware logs the request and response
+// WriteHeader records the status before writing it
+func (rec *statusRecorder) WriteHeader(statusCode int) {
+	if !rec.wroteHeader {
+		rec.statusCode = statusCode
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write counts the bytes of the body, which are sent with a 200 status when none was written
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += n
+	return n, err
+}
+
+// loggingMiddleware logs the request and response with the logger of the request, and records
+// its metrics by route template
 func loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
+		route := routeTemplate(r)
 
-		logrus.WithFields(logrus.Fields{
+		entry := logger.FromContext(r.Context()).WithFields(logrus.Fields{
 			"method": r.Method,
-			"url":    r.URL.Path,
-			"remote": r.RemoteAddr,
-			"agent":  r.UserAgent(),
-		}).Info("Incoming HTTP request")
+			"path":   r.URL.Path,
+			"route":  route,
+		})
+		if spanContext := trace.SpanContextFromContext(r.Context()); spanContext.HasTraceID() {
+			entry = entry.WithField("trace_id", spanContext.TraceID().String())
+		}
+		r = r.WithContext(logger.WithContext(r.Context(), entry))
+
+		entry.WithFields(logrus.Fields{
+			"remote":     r.RemoteAddr,
+			"user_agent": r.UserAgent(),
+		}).Debug("Incoming HTTP request")
 
 		rec := statusRecorder{
 			ResponseWriter: w,
@@ -35,51 +75,205 @@ func loggingMiddleware(next http.Handler) http.Handler {
 		}
 
 		next.ServeHTTP(&rec, r)
+		duration := time.Since(start)
+
+		completed := entry.WithFields(logrus.Fields{
+			"status":      rec.statusCode,
+			"bytes":       rec.bytes,
+			"duration_ms": float64(duration.Microseconds()) / 1000,
+		})
+		if rec.statusCode >= http.StatusInternalServerError {
+			completed.Error("Completed HTTP request")
+		} else {
+			completed.Info("Completed HTTP request")
+		}
+
+		metrics.HTTPRequests.WithLabelValues(route, r.Method, strconv.Itoa(rec.statusCode)).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(route, r.Method).Observe(duration.Seconds())
+	})
+}
+
+// tracingMiddleware starts a span of the route, continuing the trace of the W3C traceparent header
+func tracingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+		route := routeTemplate(r)
+		ctx, span := tracing.Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(r.URL.Path),
+				semconv.UserAgentOriginal(r.UserAgent()),
+			),
+		)
+		defer span.End()
+
+		rec := statusRecorder{
+			ResponseWriter: w,
+			statusCode:     http.StatusOK,
+		}
 
-		logrus.WithFields(logrus.Fields{
-			"status":   rec.statusCode,
-			"duration": time.Since(start).String(),
-		}).Info("Completed HTTP request")
+		next.ServeHTTP(&rec, r.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(rec.statusCode))
+		if rec.statusCode >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(rec.statusCode))
+		}
 	})
 }
 
+// routeTemplate gets the template of the matched route, which keeps the path variables out of labels and span names
+func routeTemplate(r *http.Request) string {
+	if current := mux.CurrentRoute(r); current != nil {
+		if template, err := current.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+	return "unmatched"
+}
+
+// publicRouter creates a subrouter whose routes can be called without authentication
+func publicRouter(parent *mux.Router, prefix string) *mux.Router {
+	return parent.PathPrefix(prefix).Subrouter()
+}
+
+// protectedRouter creates a subrouter whose routes require an authenticated caller
+func protectedRouter(parent *mux.Router, prefix string, authenticate mux.MiddlewareFunc) *mux.Router {
+	router := parent.PathPrefix(prefix).Subrouter()
+	router.Use(authenticate)
+	return router
+}
+
 // NewRouter creates a new router
 func NewRouter(
 	indexHandler *handlers.IndexHandler,
+	healthHandler *handlers.HealthHandler,
 	userHandler *handlers.UserHandler,
+	passwordHandler *handlers.PasswordHandler,
+	mfaHandler *handlers.MFAHandler,
+	roleHandler *handlers.RoleHandler,
+	permissionHandler *handlers.PermissionHandler,
+	groupHandler *handlers.GroupHandler,
+	userAdminHandler *handlers.UserAdminHandler,
+	authenticate mux.MiddlewareFunc,
+	authorization *middleware.Authorization,
 ) http.Handler {
-	log.Println("Settings up router...")
+	logger.Base().Info("Setting up router")
 
 	r := mux.NewRouter()
 
-	r.Use(loggingMiddleware) // logging
+	// Unknown routes and methods get a problem response too
+	r.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		api.SendError(w, req, utils.ErrRouteNotFound)
+	})
+	r.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		api.SendError(w, req, utils.ErrMethodNotAllowed)
+	})
+
+	r.Use(middleware.RequestID) // request ID
+	r.Use(tracingMiddleware)    // tracing
+	r.Use(loggingMiddleware)    // logging
 
 	r.PathPrefix("/docs/").Handler(httpSwagger.WrapHandler)
 
+	// Probes of the orchestrator
+	r.HandleFunc("/healthz", healthHandler.Live).Methods(http.MethodGet)
+	r.HandleFunc("/readyz", healthHandler.Ready).Methods(http.MethodGet)
+	r.Handle("/metrics", metrics.Handler()).Methods(http.MethodGet)
+
 	// Routes v1
 	prefixRouteV1 := r.PathPrefix("/api/v1").Subrouter()
 
-	indexRouter := prefixRouteV1.PathPrefix("/").Subrouter()
+	indexRouter := publicRouter(prefixRouteV1, "/")
 	indexRouter.HandleFunc("/index", handlers.Index).Methods(http.MethodGet)
 
 	// Routes for users
-	userRouter := prefixRouteV1.PathPrefix("/user").Subrouter()
+	userRouter := publicRouter(prefixRouteV1, "/user")
 	userRouter.HandleFunc("/pre-register", userHandler.PreRegister).Methods(http.MethodPost)
+	userRouter.HandleFunc("/pre-register/resend", userHandler.ResendOTP).Methods(http.MethodPost)
 	userRouter.HandleFunc("/register", userHandler.Register).Methods(http.MethodPost)
+	userRouter.HandleFunc("/login", userHandler.Login).Methods(http.MethodPost)
+	userRouter.HandleFunc("/login/mfa", mfaHandler.Challenge).Methods(http.MethodPost)
+	userRouter.HandleFunc("/token/refresh", userHandler.RefreshToken).Methods(http.MethodPost)
+	userRouter.HandleFunc("/password/forgot", passwordHandler.Forgot).Methods(http.MethodPost)
+	userRouter.HandleFunc("/password/reset", passwordHandler.Reset).Methods(http.MethodPost)
+
+	// Routes for authenticated users
+	authUserRouter := protectedRouter(prefixRouteV1, "/user", authenticate)
+	authUserRouter.HandleFunc("/logout", userHandler.Logout).Methods(http.MethodPost)
+	authUserRouter.HandleFunc("/me", userHandler.Me).Methods(http.MethodGet)
+	authUserRouter.HandleFunc("/me/security", mfaHandler.SecurityProfile).Methods(http.MethodGet)
+	authUserRouter.HandleFunc("/password/change", passwordHandler.Change).Methods(http.MethodPost)
+	authUserRouter.HandleFunc("/mfa/totp", mfaHandler.Enroll).Methods(http.MethodPost)
+	authUserRouter.HandleFunc("/mfa/totp/confirm", mfaHandler.Confirm).Methods(http.MethodPost)
+	authUserRouter.HandleFunc("/mfa/totp/disable", mfaHandler.Disable).Methods(http.MethodPost)
+	authUserRouter.HandleFunc("/mfa/recovery-codes", mfaHandler.RecoveryCodes).Methods(http.MethodPost)
+
+	// Each admin route declares the permissions it requires, the other routes of their routers are denied
+
+	// Routes for roles
+	roleRouter := authorization.Router(protectedRouter(prefixRouteV1, "/roles", authenticate))
+	roleRouter.Handle("", roleHandler.List, "read:role").Methods(http.MethodGet)
+	roleRouter.Handle("", roleHandler.Create, "create:role").Methods(http.MethodPost)
+	roleRouter.Handle("/{uuid}", roleHandler.Get, "read:role").Methods(http.MethodGet)
+	roleRouter.Handle("/{uuid}", roleHandler.Update, "update:role").Methods(http.MethodPut)
+	roleRouter.Handle("/{uuid}", roleHandler.Delete, "delete:role").Methods(http.MethodDelete)
+	roleRouter.Handle("/{uuid}/permissions", roleHandler.ListPermissions, "read:role").Methods(http.MethodGet)
+	roleRouter.Handle("/{uuid}/permissions/{permission_uuid}", roleHandler.AttachPermission, "update:role").Methods(http.MethodPut)
+	roleRouter.Handle("/{uuid}/permissions/{permission_uuid}", roleHandler.DetachPermission, "update:role").Methods(http.MethodDelete)
+
+	// Routes for permissions
+	permissionRouter := authorization.Router(protectedRouter(prefixRouteV1, "/permissions", authenticate))
+	permissionRouter.Handle("", permissionHandler.List, "read:permission").Methods(http.MethodGet)
+	permissionRouter.Handle("", permissionHandler.Create, "create:permission").Methods(http.MethodPost)
+	permissionRouter.Handle("/{uuid}", permissionHandler.Get, "read:permission").Methods(http.MethodGet)
+	permissionRouter.Handle("/{uuid}", permissionHandler.Update, "update:permission").Methods(http.MethodPut)
+	permissionRouter.Handle("/{uuid}", permissionHandler.Delete, "delete:permission").Methods(http.MethodDelete)
+
+	// Routes for groups
+	groupRouter := authorization.Router(protectedRouter(prefixRouteV1, "/groups", authenticate))
+	groupRouter.Handle("", groupHandler.List, "read:group").Methods(http.MethodGet)
+	groupRouter.Handle("", groupHandler.Create, "create:group").Methods(http.MethodPost)
+	groupRouter.Handle("/{uuid}", groupHandler.Get, "read:group").Methods(http.MethodGet)
+	groupRouter.Handle("/{uuid}", groupHandler.Update, "update:group").Methods(http.MethodPut)
+	groupRouter.Handle("/{uuid}", groupHandler.Delete, "delete:group").Methods(http.MethodDelete)
+	groupRouter.Handle("/{uuid}/members", groupHandler.ListMembers, "read:group").Methods(http.MethodGet)
+	groupRouter.Handle("/{uuid}/members/{user_uuid}", groupHandler.AddMember, "update:group", "update:role").Methods(http.MethodPut)
+	groupRouter.Handle("/{uuid}/members/{user_uuid}", groupHandler.RemoveMember, "update:group").Methods(http.MethodDelete)
+	groupRouter.Handle("/{uuid}/roles", groupHandler.ListRoles, "read:group").Methods(http.MethodGet)
+	groupRouter.Handle("/{uuid}/roles/{role_uuid}", groupHandler.AssignRole, "update:group", "update:role").Methods(http.MethodPut)
+	groupRouter.Handle("/{uuid}/roles/{role_uuid}", groupHandler.RevokeRole, "update:group", "update:role").Methods(http.MethodDelete)
+
+	// Routes for managing users
+	usersRouter := authorization.Router(protectedRouter(prefixRouteV1, "/users", authenticate))
+	usersRouter.Handle("", userAdminHandler.List, "read:user").Methods(http.MethodGet)
+	usersRouter.Handle("/{uuid}", userAdminHandler.Get, "read:user").Methods(http.MethodGet)
+	usersRouter.Handle("/{uuid}", userAdminHandler.Update, "update:user").Methods(http.MethodPatch)
+	usersRouter.Handle("/{uuid}", userAdminHandler.Delete, "delete:user").Methods(http.MethodDelete)
+	usersRouter.Handle("/{uuid}/restore", userAdminHandler.Restore, "delete:user").Methods(http.MethodPost)
+	usersRouter.Handle("/{uuid}/block", userAdminHandler.Block, "update:user").Methods(http.MethodPost)
+	usersRouter.Handle("/{uuid}/unblock", userAdminHandler.Unblock, "update:user").Methods(http.MethodPost)
+	usersRouter.Handle("/{uuid}/roles", roleHandler.ListUserRoles, "read:user").Methods(http.MethodGet)
+	usersRouter.Handle("/{uuid}/roles/{role_uuid}", roleHandler.AssignRole, "update:user", "update:role").Methods(http.MethodPut)
+	usersRouter.Handle("/{uuid}/roles/{role_uuid}", roleHandler.RevokeRole, "update:user", "update:role").Methods(http.MethodDelete)
 
-	log.Println("List all routes:")
 	r.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
 		pathTemplate, err := route.GetPathTemplate()
 		if err == nil {
 			methods, _ := route.GetMethods()
 			if len(methods) > 0 {
-				log.Printf("Route found: %s - Methods: %v", pathTemplate, methods)
+				logger.Base().WithFields(logrus.Fields{
+					"route":   pathTemplate,
+					"methods": methods,
+				}).Debug("Route found")
 			}
 		}
 		return nil
 	})
 
-	log.Println("Setting up router... Done!")
+	logger.Base().Info("Router set up")
 
 	return r
 }
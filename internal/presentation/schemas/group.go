@@ -0,0 +1,59 @@
+package schemas
+
+import (
+	"time"
+
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+)
+
+type GroupInput struct {
+	Name        string `json:"name" validate:"required" example:"platform-team"`
+	Description string `json:"description" example:"Platform engineering team"`
+}
+
+type GroupOutput struct {
+	UUID        string    `json:"uuid" example:"0b6d8f3e-8a55-4f7e-9a8e-2c1d6f3e8a55"`
+	Name        string    `json:"name" example:"platform-team"`
+	Description string    `json:"description" example:"Platform engineering team"`
+	CreatedAt   time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt   time.Time `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+}
+
+type GroupMemberOutput struct {
+	UUID  string `json:"uuid" example:"3f0c7a1e-5b2d-4e8a-9c6f-1d2e3f4a5b6c"`
+	Name  string `json:"name" example:"John Doe"`
+	Email string `json:"email" example:"john.doe@mail.com"`
+}
+
+// NewGroupOutput creates the output of a group
+func NewGroupOutput(group *entity.Group) *GroupOutput {
+	return &GroupOutput{
+		UUID:        group.UUID,
+		Name:        group.Name,
+		Description: group.Description,
+		CreatedAt:   group.CreatedAt,
+		UpdatedAt:   group.UpdatedAt,
+	}
+}
+
+// NewGroupOutputs creates the outputs of a list of groups
+func NewGroupOutputs(groups []*entity.Group) []*GroupOutput {
+	outputs := make([]*GroupOutput, 0, len(groups))
+	for _, group := range groups {
+		outputs = append(outputs, NewGroupOutput(group))
+	}
+	return outputs
+}
+
+// NewGroupMemberOutputs creates the outputs of the members of a group
+func NewGroupMemberOutputs(users []*entity.User) []*GroupMemberOutput {
+	outputs := make([]*GroupMemberOutput, 0, len(users))
+	for _, user := range users {
+		outputs = append(outputs, &GroupMemberOutput{
+			UUID:  user.UUID,
+			Name:  user.Name,
+			Email: user.Email,
+		})
+	}
+	return outputs
+}
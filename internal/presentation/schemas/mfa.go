@@ -0,0 +1,50 @@
+package schemas
+
+import (
+	"encoding/base64"
+	"time"
+)
+
+type TOTPEnrollmentOutput struct {
+	Secret string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	URI    string `json:"uri" example:"otpauth://totp/Licentia%20Usoris:example@mail.com?algorithm=SHA1&digits=6&issuer=Licentia%20Usoris&period=30&secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	QRCode string `json:"qr_code" example:"data:image/png;base64,iVBORw0KGgo..."`
+}
+
+type MFACodeInput struct {
+	Code string `json:"code" validate:"required" example:"123456"`
+}
+
+type DisableMFAInput struct {
+	Password string `json:"password" validate:"required" example:"Secret@123"`
+	Code     string `json:"code" validate:"required" example:"123456"`
+}
+
+// MFAChallengeInput completes a login with a code of the authenticator app or a recovery code
+type MFAChallengeInput struct {
+	MFAToken string `json:"mfa_token" validate:"required" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	Code     string `json:"code" validate:"required" example:"123456"`
+}
+
+type RecoveryCodesInput struct {
+	Password string `json:"password" validate:"required" example:"Secret@123"`
+}
+
+type RecoveryCodesOutput struct {
+	Codes []string `json:"codes" example:"k7m2q-x9fht"`
+}
+
+type SecurityProfileOutput struct {
+	MFAEnabled             bool       `json:"mfa_enabled" example:"true"`
+	MFAEnabledAt           *time.Time `json:"mfa_enabled_at,omitempty" example:"2024-01-01T00:00:00Z"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining" example:"10"`
+}
+
+// NewTOTPEnrollmentOutput creates the enrollment output, with the QR code PNG as a data URI
+func NewTOTPEnrollmentOutput(secret, uri string, qrCode []byte) *TOTPEnrollmentOutput {
+	return &TOTPEnrollmentOutput{
+		Secret: secret,
+		URI:    uri,
+		QRCode: "data:image/png;base64," + base64.StdEncoding.EncodeToString(qrCode),
+	}
+}
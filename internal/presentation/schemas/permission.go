@@ -0,0 +1,40 @@
+package schemas
+
+import (
+	"time"
+
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+)
+
+type PermissionInput struct {
+	Name        string `json:"name" validate:"required" example:"read:user"`
+	Description string `json:"description" example:"Read user information"`
+}
+
+type PermissionOutput struct {
+	UUID        string    `json:"uuid" example:"6f1c2a52-2f0e-4c43-9d54-6b6f3f1c2a52"`
+	Name        string    `json:"name" example:"read:user"`
+	Description string    `json:"description" example:"Read user information"`
+	CreatedAt   time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt   time.Time `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+}
+
+// NewPermissionOutput creates the output of a permission
+func NewPermissionOutput(permission *entity.Permission) *PermissionOutput {
+	return &PermissionOutput{
+		UUID:        permission.UUID,
+		Name:        permission.Name,
+		Description: permission.Description,
+		CreatedAt:   permission.CreatedAt,
+		UpdatedAt:   permission.UpdatedAt,
+	}
+}
+
+// NewPermissionOutputs creates the outputs of a list of permissions
+func NewPermissionOutputs(permissions []*entity.Permission) []*PermissionOutput {
+	outputs := make([]*PermissionOutput, 0, len(permissions))
+	for _, permission := range permissions {
+		outputs = append(outputs, NewPermissionOutput(permission))
+	}
+	return outputs
+}
@@ -0,0 +1,40 @@
+package schemas
+
+import (
+	"time"
+
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+)
+
+type RoleInput struct {
+	Name        string `json:"name" validate:"required" example:"support"`
+	Description string `json:"description" example:"Support staff"`
+}
+
+type RoleOutput struct {
+	UUID        string    `json:"uuid" example:"6f1c2a52-2f0e-4c43-9d54-6b6f3f1c2a52"`
+	Name        string    `json:"name" example:"support"`
+	Description string    `json:"description" example:"Support staff"`
+	CreatedAt   time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt   time.Time `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+}
+
+// NewRoleOutput creates the output of a role
+func NewRoleOutput(role *entity.Role) *RoleOutput {
+	return &RoleOutput{
+		UUID:        role.UUID,
+		Name:        role.Name,
+		Description: role.Description,
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
+
+// NewRoleOutputs creates the outputs of a list of roles
+func NewRoleOutputs(roles []*entity.Role) []*RoleOutput {
+	outputs := make([]*RoleOutput, 0, len(roles))
+	for _, role := range roles {
+		outputs = append(outputs, NewRoleOutput(role))
+	}
+	return outputs
+}
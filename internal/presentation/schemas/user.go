@@ -1,5 +1,11 @@
 package schemas
 
+import (
+	"time"
+
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+)
+
 type PreRegistrationInput struct {
 	Name        string `json:"name" validate:"required,name" example:"John Doe"`
 	Email       string `json:"email" validate:"required,email" example:"example@mail.com"`
@@ -13,7 +19,129 @@ type VerifyOTPInput struct {
 	OTP   string `json:"otp" validate:"required" example:"123456"`
 }
 
+type ResendOTPInput struct {
+	Email string `json:"email" validate:"required,email" example:"example@mail.com"`
+}
+
 type LoginInput struct {
 	Email    string `json:"email" validate:"required,email" example:"example@mail.com"`
 	Password string `json:"password" validate:"required,password" example:"password123"`
 }
+
+type RefreshTokenInput struct {
+	RefreshToken string `json:"refresh_token" validate:"required" example:"Zm9vYmFyYmF6cXV4Zm9vYmFyYmF6cXV4Zm9vYmFyYmF6"`
+}
+
+type LogoutInput struct {
+	RefreshToken string `json:"refresh_token" example:"Zm9vYmFyYmF6cXV4Zm9vYmFyYmF6cXV4Zm9vYmFyYmF6"`
+}
+
+type TokenOutput struct {
+	AccessToken  string `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	RefreshToken string `json:"refresh_token" example:"Zm9vYmFyYmF6cXV4Zm9vYmFyYmF6cXV4Zm9vYmFyYmF6"`
+	TokenType    string `json:"token_type" example:"Bearer"`
+	ExpiresIn    int64  `json:"expires_in" example:"900"`
+}
+
+// LoginOutput carries the tokens of the session, or the MFA challenge
+// to complete when the user enabled two-factor authentication
+type LoginOutput struct {
+	MFARequired  bool   `json:"mfa_required" example:"false"`
+	MFAToken     string `json:"mfa_token,omitempty" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	MFAExpiresIn int64  `json:"mfa_expires_in,omitempty" example:"300"`
+	*TokenOutput
+}
+
+type UserOutput struct {
+	UUID            string     `json:"uuid" example:"6f1c2a52-2f0e-4c43-9d54-6b6f3f1c2a52"`
+	Name            string     `json:"name" example:"John Doe"`
+	Email           string     `json:"email" example:"example@mail.com"`
+	DateOfBirth     string     `json:"date_of_birth,omitempty" example:"1990-01-01"`
+	PhoneNumber     string     `json:"phone_number,omitempty" example:"08123456789"`
+	IsEmailVerified bool       `json:"is_email_verified" example:"true"`
+	IsBlocked       bool       `json:"is_blocked" example:"false"`
+	IsDeleted       bool       `json:"is_deleted" example:"false"`
+	Roles           []string   `json:"roles,omitempty" example:"user"`
+	CreatedAt       time.Time  `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt       time.Time  `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty" example:"2024-01-01T00:00:00Z"`
+	LastLogin       *time.Time `json:"last_login,omitempty" example:"2024-01-01T00:00:00Z"`
+	BlockedAt       *time.Time `json:"blocked_at,omitempty" example:"2024-01-01T00:00:00Z"`
+	BlockedReason   string     `json:"blocked_reason,omitempty" example:"Chargeback fraud under investigation"`
+}
+
+// NewUserOutput creates the output of a user without its credentials
+func NewUserOutput(user *entity.User, roles []string) *UserOutput {
+	output := &UserOutput{
+		UUID:            user.UUID,
+		Name:            user.Name,
+		Email:           user.Email,
+		PhoneNumber:     user.PhoneNumber,
+		IsEmailVerified: user.IsEmailVerified,
+		IsBlocked:       user.IsBlocked,
+		IsDeleted:       user.IsDeleted,
+		Roles:           roles,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+	}
+
+	if !user.DOB.IsZero() {
+		output.DateOfBirth = user.DOB.Format("2006-01-02")
+	}
+
+	if !user.DeletedAt.IsZero() {
+		deletedAt := user.DeletedAt
+		output.DeletedAt = &deletedAt
+	}
+
+	if !user.LastLogin.IsZero() {
+		lastLogin := user.LastLogin
+		output.LastLogin = &lastLogin
+	}
+
+	if !user.BlockedAt.IsZero() {
+		blockedAt := user.BlockedAt
+		output.BlockedAt = &blockedAt
+		output.BlockedReason = user.BlockedReason
+	}
+
+	return output
+}
+
+type UserFilterInput struct {
+	Email           string
+	Name            string
+	IsBlocked       *bool
+	IsDeleted       *bool
+	IsEmailVerified *bool
+	Sort            string
+	Order           string
+	Page            int
+	PageSize        int
+}
+
+type BlockUserInput struct {
+	Reason string `json:"reason" validate:"required" example:"Chargeback fraud under investigation"`
+}
+
+type UpdateUserInput struct {
+	Name            *string `json:"name,omitempty" example:"John Doe"`
+	Email           *string `json:"email,omitempty" example:"example@mail.com"`
+	DateOfBirth     *string `json:"date_of_birth,omitempty" example:"1990-01-01"`
+	PhoneNumber     *string `json:"phone_number,omitempty" example:"08123456789"`
+	IsEmailVerified *bool   `json:"is_email_verified,omitempty" example:"true"`
+}
+
+type ForgotPasswordInput struct {
+	Email string `json:"email" validate:"required,email" example:"example@mail.com"`
+}
+
+type ResetPasswordInput struct {
+	Token    string `json:"token" validate:"required" example:"Zq3o1d1u2pB3sN0v8lW5xY7tR4mK6jH9gF2eD1cA0bE"`
+	Password string `json:"password" validate:"required,password" example:"NewSecret@123"`
+}
+
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"current_password" validate:"required" example:"Secret@123"`
+	NewPassword     string `json:"new_password" validate:"required,password" example:"NewSecret@123"`
+}
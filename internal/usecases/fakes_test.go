@@ -0,0 +1,551 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/edutav/licentia-usoris/infrastructure/auth"
+	"github.com/edutav/licentia-usoris/infrastructure/email"
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+	"github.com/edutav/licentia-usoris/internal/domain/reporitory"
+	"github.com/edutav/licentia-usoris/internal/utils"
+)
+
+// The fakes embed the repository interfaces, so calling a method a test does not
+// expect panics on the nil interface
+
+// fakeUserRepository keeps the users in memory
+type fakeUserRepository struct {
+	reporitory.UserRepository
+
+	mu          sync.Mutex
+	users       map[string]*entity.User
+	permissions map[string][]string
+}
+
+func newFakeUserRepository(users ...*entity.User) *fakeUserRepository {
+	repo := &fakeUserRepository{
+		users:       map[string]*entity.User{},
+		permissions: map[string][]string{},
+	}
+	for _, user := range users {
+		repo.users[user.UUID] = user
+	}
+	return repo
+}
+
+func (r *fakeUserRepository) GetUserByUUID(ctx context.Context, userUUID string) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userUUID]
+	if !ok {
+		return nil, utils.ErrUserNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *fakeUserRepository) GetUserByEmail(ctx context.Context, email string) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, user := range r.users {
+		if user.Email == email {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, utils.ErrUserNotFound
+}
+
+func (r *fakeUserRepository) UpdateUser(ctx context.Context, userUUID string, update reporitory.UserUpdate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userUUID]
+	if !ok {
+		return utils.ErrUserNotFound
+	}
+	if update.Name != nil {
+		user.Name = *update.Name
+	}
+	if update.Email != nil {
+		user.Email = *update.Email
+	}
+	if update.IsEmailVerified != nil {
+		user.IsEmailVerified = *update.IsEmailVerified
+	}
+	user.UpdatedAt = update.UpdatedAt
+	return nil
+}
+
+func (r *fakeUserRepository) DeleteUser(ctx context.Context, userUUID string, deletedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userUUID]
+	if !ok || user.IsDeleted {
+		return utils.ErrUserAlreadyDeleted
+	}
+	user.IsDeleted = true
+	user.DeletedAt = deletedAt
+	return nil
+}
+
+func (r *fakeUserRepository) BlockUser(ctx context.Context, userUUID, reason string, blockedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userUUID]
+	if !ok || user.IsBlocked {
+		return utils.ErrUserAlreadyBlocked
+	}
+	user.IsBlocked = true
+	return nil
+}
+
+func (r *fakeUserRepository) UnblockUser(ctx context.Context, userUUID string, updatedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userUUID]
+	if !ok || !user.IsBlocked {
+		return utils.ErrUserNotBlocked
+	}
+	user.IsBlocked = false
+	return nil
+}
+
+func (r *fakeUserRepository) UpdatePassword(ctx context.Context, userUUID, passwordHash string, updatedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userUUID]
+	if !ok {
+		return utils.ErrUserNotFound
+	}
+	user.PasswordHash = passwordHash
+	user.UpdatedAt = updatedAt
+	return nil
+}
+
+func (r *fakeUserRepository) GetPasswordHistory(ctx context.Context, userUUID string, limit int) ([]string, error) {
+	return nil, nil
+}
+
+func (r *fakeUserRepository) UpdateLastLogin(ctx context.Context, userUUID string, lastLogin time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if user, ok := r.users[userUUID]; ok {
+		user.LastLogin = lastLogin
+	}
+	return nil
+}
+
+func (r *fakeUserRepository) GetUserRoles(ctx context.Context, userUUID string) ([]string, error) {
+	return nil, nil
+}
+
+func (r *fakeUserRepository) GetUserPermissions(ctx context.Context, userUUID string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.permissions[userUUID], nil
+}
+
+// fakeRoleRepository keeps the roles, their permissions and the user assignments in memory
+type fakeRoleRepository struct {
+	reporitory.RoleRepository
+
+	mu          sync.Mutex
+	permissions map[string][]string
+	assigned    map[string][]string
+}
+
+func newFakeRoleRepository(permissions map[string][]string) *fakeRoleRepository {
+	return &fakeRoleRepository{
+		permissions: permissions,
+		assigned:    map[string][]string{},
+	}
+}
+
+func (r *fakeRoleRepository) GetRoleByUUID(ctx context.Context, roleUUID string) (*entity.Role, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.permissions[roleUUID]; !ok {
+		return nil, utils.ErrRoleNotFound
+	}
+	return &entity.Role{UUID: roleUUID, Name: roleUUID}, nil
+}
+
+func (r *fakeRoleRepository) ListRolePermissions(ctx context.Context, roleUUID string) ([]*entity.Permission, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	permissions := make([]*entity.Permission, 0, len(r.permissions[roleUUID]))
+	for _, name := range r.permissions[roleUUID] {
+		permissions = append(permissions, &entity.Permission{UUID: name, Name: name})
+	}
+	return permissions, nil
+}
+
+func (r *fakeRoleRepository) AssignRole(ctx context.Context, userUUID, roleUUID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.assigned[userUUID] = append(r.assigned[userUUID], roleUUID)
+	return nil
+}
+
+func (r *fakeRoleRepository) AttachPermission(ctx context.Context, roleUUID, permissionUUID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.permissions[roleUUID] = append(r.permissions[roleUUID], permissionUUID)
+	return nil
+}
+
+// fakeGroupRepository keeps the roles and the members of the groups in memory
+type fakeGroupRepository struct {
+	reporitory.GroupRepository
+
+	mu      sync.Mutex
+	roles   map[string][]string
+	members map[string][]string
+}
+
+func newFakeGroupRepository(roles map[string][]string) *fakeGroupRepository {
+	return &fakeGroupRepository{
+		roles:   roles,
+		members: map[string][]string{},
+	}
+}
+
+func (r *fakeGroupRepository) GetGroupByUUID(ctx context.Context, groupUUID string) (*entity.Group, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.roles[groupUUID]; !ok {
+		return nil, utils.ErrGroupNotFound
+	}
+	return &entity.Group{UUID: groupUUID, Name: groupUUID}, nil
+}
+
+func (r *fakeGroupRepository) ListGroupRoles(ctx context.Context, groupUUID string) ([]*entity.Role, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	roles := make([]*entity.Role, 0, len(r.roles[groupUUID]))
+	for _, roleUUID := range r.roles[groupUUID] {
+		roles = append(roles, &entity.Role{UUID: roleUUID, Name: roleUUID})
+	}
+	return roles, nil
+}
+
+func (r *fakeGroupRepository) AddMember(ctx context.Context, groupUUID, userUUID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.members[groupUUID] = append(r.members[groupUUID], userUUID)
+	return nil
+}
+
+// fakePermissionRepository knows the permissions by name, using the name as their UUID
+type fakePermissionRepository struct {
+	reporitory.PermissionRepository
+
+	names []string
+}
+
+func (r *fakePermissionRepository) GetPermissionByUUID(ctx context.Context, permissionUUID string) (*entity.Permission, error) {
+	if !slices.Contains(r.names, permissionUUID) {
+		return nil, utils.ErrPermissionNotFound
+	}
+	return &entity.Permission{UUID: permissionUUID, Name: permissionUUID}, nil
+}
+
+// fakeRefreshTokenRepository keeps the refresh tokens in memory by hash
+type fakeRefreshTokenRepository struct {
+	reporitory.RefreshTokenRepository
+
+	mu     sync.Mutex
+	tokens map[string]*entity.RefreshToken
+	nextID int
+}
+
+func newFakeRefreshTokenRepository() *fakeRefreshTokenRepository {
+	return &fakeRefreshTokenRepository{tokens: map[string]*entity.RefreshToken{}}
+}
+
+func (r *fakeRefreshTokenRepository) CreateRefreshToken(ctx context.Context, token *entity.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.create(token)
+	return nil
+}
+
+func (r *fakeRefreshTokenRepository) create(token *entity.RefreshToken) {
+	r.nextID++
+	token.UUID = fmt.Sprintf("refresh-token-%d", r.nextID)
+	if token.FamilyID == "" {
+		token.FamilyID = token.UUID
+	}
+	copied := *token
+	r.tokens[token.TokenHash] = &copied
+}
+
+func (r *fakeRefreshTokenRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[tokenHash]
+	if !ok {
+		return nil, utils.ErrInvalidRefreshToken
+	}
+	copied := *token
+	return &copied, nil
+}
+
+func (r *fakeRefreshTokenRepository) RotateRefreshToken(ctx context.Context, current *entity.RefreshToken, next *entity.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := r.tokens[current.TokenHash]
+	if stored == nil || stored.IsRotated() || stored.IsRevoked() {
+		return utils.ErrRefreshTokenReused
+	}
+
+	r.create(next)
+	stored.RotatedAt = time.Now().UTC()
+	stored.ReplacedBy = next.UUID
+	return nil
+}
+
+func (r *fakeRefreshTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID && !token.IsRevoked() {
+			token.RevokedAt = time.Now().UTC()
+		}
+	}
+	return nil
+}
+
+func (r *fakeRefreshTokenRepository) RevokeUserRefreshTokens(ctx context.Context, userUUID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.UserUUID == userUUID && !token.IsRevoked() {
+			token.RevokedAt = time.Now().UTC()
+		}
+	}
+	return nil
+}
+
+// token gets the stored refresh token of the value
+func (r *fakeRefreshTokenRepository) token(value string) *entity.RefreshToken {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.tokens[auth.HashOpaqueToken(value)]
+}
+
+// fakeTokenService issues unsigned access tokens
+type fakeTokenService struct {
+	auth.TokenService
+
+	mu     sync.Mutex
+	nextID int
+}
+
+func (s *fakeTokenService) GenerateAccessToken(
+	userUUID, email string, roles, permissions []string, minIssuedAt time.Time,
+) (*auth.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	id := fmt.Sprintf("access-token-%d", s.nextID)
+	return &auth.Token{
+		Value:     id,
+		ID:        id,
+		ExpiresAt: time.Now().UTC().Add(s.AccessTokenTTL()),
+	}, nil
+}
+
+func (s *fakeTokenService) AccessTokenTTL() time.Duration {
+	return 15 * time.Minute
+}
+
+// fakeBlacklist keeps the revocations in memory, with the semantics of the SQL blacklist
+type fakeBlacklist struct {
+	auth.TokenBlacklist
+
+	mu          sync.Mutex
+	spent       map[string]bool
+	revocations map[string]time.Time
+}
+
+func newFakeBlacklist() *fakeBlacklist {
+	return &fakeBlacklist{
+		spent:       map[string]bool{},
+		revocations: map[string]time.Time{},
+	}
+}
+
+func (b *fakeBlacklist) Spend(ctx context.Context, tokenID string, expiresAt time.Time) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.spent[tokenID] {
+		return false, nil
+	}
+	b.spent[tokenID] = true
+	return true, nil
+}
+
+func (b *fakeBlacklist) RevokeUser(ctx context.Context, userUUID string, revokedAt, expiresAt time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if revokedAt.After(b.revocations[userUUID]) {
+		b.revocations[userUUID] = revokedAt
+	}
+	return nil
+}
+
+func (b *fakeBlacklist) IsUserRevoked(ctx context.Context, userUUID string, issuedAt time.Time) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.revocations[userUUID].After(issuedAt), nil
+}
+
+// fakeEmailSender sends nothing
+type fakeEmailSender struct {
+	email.EmailSender
+}
+
+func (s *fakeEmailSender) SendPasswordChanged(ctx context.Context, to string) error {
+	return nil
+}
+
+// fakeMFARepository keeps the enrollments and the recovery codes in memory
+type fakeMFARepository struct {
+	reporitory.MFARepository
+
+	mu            sync.Mutex
+	enrollments   map[string]*entity.UserMFA
+	recoveryCodes []*entity.RecoveryCode
+}
+
+func (r *fakeMFARepository) GetMFA(ctx context.Context, userUUID string) (*entity.UserMFA, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mfa, ok := r.enrollments[userUUID]
+	if !ok {
+		return nil, utils.ErrMFANotEnrolled
+	}
+	copied := *mfa
+	return &copied, nil
+}
+
+func (r *fakeMFARepository) ListUnusedRecoveryCodes(ctx context.Context, userUUID string) ([]*entity.RecoveryCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var codes []*entity.RecoveryCode
+	for _, code := range r.recoveryCodes {
+		if code.UserUUID == userUUID && !code.IsUsed() {
+			copied := *code
+			codes = append(codes, &copied)
+		}
+	}
+	return codes, nil
+}
+
+func (r *fakeMFARepository) UseRecoveryCode(ctx context.Context, codeUUID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, code := range r.recoveryCodes {
+		if code.UUID == codeUUID && !code.IsUsed() {
+			code.UsedAt = time.Now().UTC()
+			return nil
+		}
+	}
+	return utils.ErrInvalidMFACode
+}
+
+// fakeVerificationCodeRepository keeps the verification codes in memory, by purpose and subject
+type fakeVerificationCodeRepository struct {
+	reporitory.VerificationCodeRepository
+
+	mu     sync.Mutex
+	codes  map[string]*entity.VerificationCode
+	nextID int
+}
+
+func newFakeVerificationCodeRepository() *fakeVerificationCodeRepository {
+	return &fakeVerificationCodeRepository{codes: map[string]*entity.VerificationCode{}}
+}
+
+func (r *fakeVerificationCodeRepository) SaveVerificationCode(ctx context.Context, code *entity.VerificationCode, issuedBefore time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	code.UUID = fmt.Sprintf("verification-code-%d", r.nextID)
+	copied := *code
+	r.codes[code.Purpose+"/"+code.Subject] = &copied
+	return nil
+}
+
+func (r *fakeVerificationCodeRepository) GetVerificationCode(ctx context.Context, purpose, subject string) (*entity.VerificationCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	code, ok := r.codes[purpose+"/"+subject]
+	if !ok {
+		return nil, utils.ErrInvalidOTP
+	}
+	copied := *code
+	return &copied, nil
+}
+
+func (r *fakeVerificationCodeRepository) AddVerificationCodeAttempt(ctx context.Context, codeUUID string, maxAttempts int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, code := range r.codes {
+		if code.UUID == codeUUID && code.Attempts < maxAttempts {
+			code.Attempts++
+			return nil
+		}
+	}
+	return utils.ErrOTPLocked
+}
+
+func (r *fakeVerificationCodeRepository) ConsumeVerificationCode(ctx context.Context, codeUUID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, code := range r.codes {
+		if code.UUID == codeUUID && !code.IsConsumed() {
+			code.ConsumedAt = time.Now().UTC()
+			return nil
+		}
+	}
+	return utils.ErrInvalidOTP
+}
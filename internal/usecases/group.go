@@ -0,0 +1,226 @@
+package usecases
+
+import (
+	"context"
+	"time"
+
+	"github.com/edutav/licentia-usoris/infrastructure/auth"
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+	"github.com/edutav/licentia-usoris/internal/domain/reporitory"
+	"github.com/edutav/licentia-usoris/internal/presentation/schemas"
+	"github.com/edutav/licentia-usoris/internal/usecases/validator"
+)
+
+type GroupUseCase interface {
+	// Create group
+	CreateGroup(ctx context.Context, input *schemas.GroupInput) (*schemas.GroupOutput, error)
+
+	// List groups
+	ListGroups(ctx context.Context) ([]*schemas.GroupOutput, error)
+
+	// Get group
+	GetGroup(ctx context.Context, groupUUID string) (*schemas.GroupOutput, error)
+
+	// Update group
+	UpdateGroup(ctx context.Context, groupUUID string, input *schemas.GroupInput) (*schemas.GroupOutput, error)
+
+	// Delete group
+	DeleteGroup(ctx context.Context, groupUUID string) error
+
+	// List the members of the group
+	ListMembers(ctx context.Context, groupUUID string) ([]*schemas.GroupMemberOutput, error)
+
+	// Add user to group, refusing groups whose roles grant permissions the principal does not hold
+	AddMember(ctx context.Context, principal *auth.Principal, groupUUID, userUUID string) error
+
+	// Remove user from group
+	RemoveMember(ctx context.Context, groupUUID, userUUID string) error
+
+	// List the roles assigned to the group
+	ListRoles(ctx context.Context, groupUUID string) ([]*schemas.RoleOutput, error)
+
+	// Assign role to group, refusing roles that grant permissions the principal does not hold
+	AssignRole(ctx context.Context, principal *auth.Principal, groupUUID, roleUUID string) error
+
+	// Revoke role from group
+	RevokeRole(ctx context.Context, groupUUID, roleUUID string) error
+}
+
+type groupUseCase struct {
+	groupRepository reporitory.GroupRepository
+	roleRepository  reporitory.RoleRepository
+	userRepository  reporitory.UserRepository
+}
+
+// NewGroupUseCase creates a new group use case
+func NewGroupUseCase(
+	groupRepository reporitory.GroupRepository,
+	roleRepository reporitory.RoleRepository,
+	userRepository reporitory.UserRepository,
+) GroupUseCase {
+	return &groupUseCase{
+		groupRepository: groupRepository,
+		roleRepository:  roleRepository,
+		userRepository:  userRepository,
+	}
+}
+
+// CreateGroup implements GroupUseCase.
+func (u *groupUseCase) CreateGroup(ctx context.Context, input *schemas.GroupInput) (*schemas.GroupOutput, error) {
+	err := validator.ValidateGroupName(input.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	group := &entity.Group{
+		Name:        input.Name,
+		Description: input.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	err = u.groupRepository.CreateGroup(ctx, group)
+	if err != nil {
+		return nil, err
+	}
+
+	return schemas.NewGroupOutput(group), nil
+}
+
+// ListGroups implements GroupUseCase.
+func (u *groupUseCase) ListGroups(ctx context.Context) ([]*schemas.GroupOutput, error) {
+	groups, err := u.groupRepository.ListGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return schemas.NewGroupOutputs(groups), nil
+}
+
+// GetGroup implements GroupUseCase.
+func (u *groupUseCase) GetGroup(ctx context.Context, groupUUID string) (*schemas.GroupOutput, error) {
+	group, err := u.groupRepository.GetGroupByUUID(ctx, groupUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return schemas.NewGroupOutput(group), nil
+}
+
+// UpdateGroup implements GroupUseCase.
+func (u *groupUseCase) UpdateGroup(ctx context.Context, groupUUID string, input *schemas.GroupInput) (*schemas.GroupOutput, error) {
+	err := validator.ValidateGroupName(input.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := u.groupRepository.GetGroupByUUID(ctx, groupUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	group.Name = input.Name
+	group.Description = input.Description
+	group.UpdatedAt = time.Now().UTC()
+
+	err = u.groupRepository.UpdateGroup(ctx, group)
+	if err != nil {
+		return nil, err
+	}
+
+	return schemas.NewGroupOutput(group), nil
+}
+
+// DeleteGroup implements GroupUseCase.
+func (u *groupUseCase) DeleteGroup(ctx context.Context, groupUUID string) error {
+	return u.groupRepository.DeleteGroup(ctx, groupUUID)
+}
+
+// ListMembers implements GroupUseCase.
+func (u *groupUseCase) ListMembers(ctx context.Context, groupUUID string) ([]*schemas.GroupMemberOutput, error) {
+	_, err := u.groupRepository.GetGroupByUUID(ctx, groupUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := u.groupRepository.ListGroupMembers(ctx, groupUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return schemas.NewGroupMemberOutputs(members), nil
+}
+
+// AddMember implements GroupUseCase.
+func (u *groupUseCase) AddMember(ctx context.Context, principal *auth.Principal, groupUUID, userUUID string) error {
+	_, err := u.groupRepository.GetGroupByUUID(ctx, groupUUID)
+	if err != nil {
+		return err
+	}
+
+	_, err = u.userRepository.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return err
+	}
+
+	// The member inherits every role of the group
+	roles, err := u.groupRepository.ListGroupRoles(ctx, groupUUID)
+	if err != nil {
+		return err
+	}
+
+	for _, role := range roles {
+		err = checkGrantable(ctx, u.userRepository, u.roleRepository, principal, role.UUID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return u.groupRepository.AddMember(ctx, groupUUID, userUUID)
+}
+
+// RemoveMember implements GroupUseCase.
+func (u *groupUseCase) RemoveMember(ctx context.Context, groupUUID, userUUID string) error {
+	return u.groupRepository.RemoveMember(ctx, groupUUID, userUUID)
+}
+
+// ListRoles implements GroupUseCase.
+func (u *groupUseCase) ListRoles(ctx context.Context, groupUUID string) ([]*schemas.RoleOutput, error) {
+	_, err := u.groupRepository.GetGroupByUUID(ctx, groupUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := u.groupRepository.ListGroupRoles(ctx, groupUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return schemas.NewRoleOutputs(roles), nil
+}
+
+// AssignRole implements GroupUseCase.
+func (u *groupUseCase) AssignRole(ctx context.Context, principal *auth.Principal, groupUUID, roleUUID string) error {
+	_, err := u.groupRepository.GetGroupByUUID(ctx, groupUUID)
+	if err != nil {
+		return err
+	}
+
+	_, err = u.roleRepository.GetRoleByUUID(ctx, roleUUID)
+	if err != nil {
+		return err
+	}
+
+	err = checkGrantable(ctx, u.userRepository, u.roleRepository, principal, roleUUID)
+	if err != nil {
+		return err
+	}
+
+	return u.groupRepository.AssignRole(ctx, groupUUID, roleUUID)
+}
+
+// RevokeRole implements GroupUseCase.
+func (u *groupUseCase) RevokeRole(ctx context.Context, groupUUID, roleUUID string) error {
+	return u.groupRepository.RevokeRole(ctx, groupUUID, roleUUID)
+}
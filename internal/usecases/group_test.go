@@ -0,0 +1,60 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/edutav/licentia-usoris/infrastructure/auth"
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+	"github.com/edutav/licentia-usoris/internal/utils"
+)
+
+func TestAddMember(t *testing.T) {
+	tests := []struct {
+		name    string
+		held    []string
+		member  string
+		group   string
+		wantErr error
+	}{
+		{"group without roles", []string{"update:group", "update:role"}, "target", "empty", nil},
+		{"group roles within the held permissions", []string{"update:group", "update:role", "read:user"}, "target", "readers", nil},
+		{"group role granting a permission not held", []string{"update:group", "update:role", "read:user"}, "target", "admins", utils.ErrRoleExceedsGrantor},
+		{"caller adding themselves to a group above them", []string{"update:group", "update:role"}, "caller", "admins", utils.ErrRoleExceedsGrantor},
+		{"unknown group", []string{"update:group", "update:role"}, "target", "missing", utils.ErrGroupNotFound},
+		{"unknown user", []string{"update:group", "update:role"}, "missing", "empty", utils.ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newFakeUserRepository(
+				&entity.User{UUID: "caller"},
+				&entity.User{UUID: "target"},
+			)
+			users.permissions["caller"] = tt.held
+
+			roles := newFakeRoleRepository(map[string][]string{
+				"reader": {"read:user"},
+				"admin":  {"read:user", "delete:user"},
+			})
+			groups := newFakeGroupRepository(map[string][]string{
+				"empty":   {},
+				"readers": {"reader"},
+				"admins":  {"reader", "admin"},
+			})
+
+			u := NewGroupUseCase(groups, roles, users)
+			err := u.AddMember(context.Background(), &auth.Principal{UserUUID: "caller"}, tt.group, tt.member)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AddMember() error = %v, want %v", err, tt.wantErr)
+			}
+
+			added := slices.Contains(groups.members[tt.group], tt.member)
+			if added != (tt.wantErr == nil) {
+				t.Errorf("member added = %v, want %v", added, tt.wantErr == nil)
+			}
+		})
+	}
+}
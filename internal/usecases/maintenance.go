@@ -0,0 +1,43 @@
+package usecases
+
+import (
+	"context"
+	"time"
+
+	"github.com/edutav/licentia-usoris/internal/domain/reporitory"
+)
+
+type MaintenanceUseCase interface {
+	// Delete the pending pre-registrations that expired
+	PurgeExpiredPreRegistrations(ctx context.Context) (int64, error)
+
+	// Delete the verification codes that expired and whose day window of sends ended
+	PurgeExpiredVerificationCodes(ctx context.Context) (int64, error)
+}
+
+type maintenanceUseCase struct {
+	userRepository             reporitory.UserRepository
+	verificationCodeRepository reporitory.VerificationCodeRepository
+}
+
+// NewMaintenanceUseCase creates a new maintenance use case
+func NewMaintenanceUseCase(
+	userRepository reporitory.UserRepository,
+	verificationCodeRepository reporitory.VerificationCodeRepository,
+) MaintenanceUseCase {
+	return &maintenanceUseCase{
+		userRepository:             userRepository,
+		verificationCodeRepository: verificationCodeRepository,
+	}
+}
+
+// PurgeExpiredPreRegistrations implements MaintenanceUseCase.
+func (u *maintenanceUseCase) PurgeExpiredPreRegistrations(ctx context.Context) (int64, error) {
+	return u.userRepository.DeleteExpiredPreRegistrations(ctx, time.Now().UTC())
+}
+
+// PurgeExpiredVerificationCodes implements MaintenanceUseCase.
+func (u *maintenanceUseCase) PurgeExpiredVerificationCodes(ctx context.Context) (int64, error) {
+	now := time.Now().UTC()
+	return u.verificationCodeRepository.DeleteExpiredVerificationCodes(ctx, now, now.Add(-verificationSendWindow))
+}
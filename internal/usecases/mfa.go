@@ -0,0 +1,378 @@
+package usecases
+
+import (
+	"context"
+	"time"
+
+	"github.com/edutav/licentia-usoris/infrastructure/auth"
+	otpapp "github.com/edutav/licentia-usoris/infrastructure/otp_app"
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+	"github.com/edutav/licentia-usoris/internal/domain/reporitory"
+	"github.com/edutav/licentia-usoris/internal/presentation/schemas"
+	"github.com/edutav/licentia-usoris/internal/usecases/validator"
+	"github.com/edutav/licentia-usoris/internal/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type MFAUseCase interface {
+	// Start the enrollment of an authenticator app, replacing a pending one
+	EnrollTOTP(ctx context.Context, principal *auth.Principal) (*schemas.TOTPEnrollmentOutput, error)
+
+	// Enable two-factor authentication with a first code of the enrolled app
+	ConfirmTOTP(ctx context.Context, principal *auth.Principal, input *schemas.MFACodeInput) error
+
+	// Disable two-factor authentication with the password and a code of the app or a recovery code
+	DisableTOTP(ctx context.Context, principal *auth.Principal, input *schemas.DisableMFAInput) error
+
+	// Complete a login with the MFA challenge token and a code of the app or a recovery code
+	VerifyChallenge(ctx context.Context, input *schemas.MFAChallengeInput) (*schemas.TokenOutput, error)
+
+	// Generate new recovery codes, replacing the previous ones
+	GenerateRecoveryCodes(ctx context.Context, principal *auth.Principal, input *schemas.RecoveryCodesInput) (*schemas.RecoveryCodesOutput, error)
+
+	// Get the two-factor authentication state of the user
+	GetSecurityProfile(ctx context.Context, principal *auth.Principal) (*schemas.SecurityProfileOutput, error)
+}
+
+// Number of recovery codes generated at once
+const recoveryCodeCount = 10
+
+type mfaUseCase struct {
+	userRepository reporitory.UserRepository
+	mfaRepository  reporitory.MFARepository
+	totp           *otpapp.TOTP
+	cipher         *auth.SecretCipher
+	tokenService   auth.TokenService
+	blacklist      auth.TokenBlacklist
+	sessions       *sessionIssuer
+}
+
+// NewMFAUseCase creates a new MFA use case
+func NewMFAUseCase(
+	userRepository reporitory.UserRepository,
+	mfaRepository reporitory.MFARepository,
+	refreshTokenRepository reporitory.RefreshTokenRepository,
+	totp *otpapp.TOTP,
+	cipher *auth.SecretCipher,
+	tokenService auth.TokenService,
+	blacklist auth.TokenBlacklist,
+	refreshTokenTTL time.Duration,
+) MFAUseCase {
+	if refreshTokenTTL <= 0 {
+		refreshTokenTTL = 30 * 24 * time.Hour
+	}
+	return &mfaUseCase{
+		userRepository: userRepository,
+		mfaRepository:  mfaRepository,
+		totp:           totp,
+		cipher:         cipher,
+		tokenService:   tokenService,
+		blacklist:      blacklist,
+		sessions: &sessionIssuer{
+			userRepository:         userRepository,
+			refreshTokenRepository: refreshTokenRepository,
+			tokenService:           tokenService,
+			refreshTokenTTL:        refreshTokenTTL,
+		},
+	}
+}
+
+// EnrollTOTP implements MFAUseCase.
+func (u *mfaUseCase) EnrollTOTP(ctx context.Context, principal *auth.Principal) (*schemas.TOTPEnrollmentOutput, error) {
+	user, err := u.activeUser(ctx, principal.UserUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := u.totp.Generate(user.Email)
+	if err != nil {
+		return nil, utils.ErrGenerateOTP
+	}
+
+	secret, err := u.cipher.Encrypt(key.Secret, user.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.mfaRepository.SavePendingMFA(ctx, &entity.UserMFA{
+		UserUUID:        user.UUID,
+		SecretEncrypted: secret,
+		CreatedAt:       time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return schemas.NewTOTPEnrollmentOutput(key.Secret, key.URI, key.QRCode), nil
+}
+
+// ConfirmTOTP implements MFAUseCase.
+func (u *mfaUseCase) ConfirmTOTP(ctx context.Context, principal *auth.Principal, input *schemas.MFACodeInput) error {
+	mfa, err := u.mfaRepository.GetMFA(ctx, principal.UserUUID)
+	if err != nil {
+		return err
+	}
+
+	if mfa.IsEnabled() {
+		return utils.ErrMFAAlreadyEnabled
+	}
+
+	step, err := u.matchCode(mfa, input.Code)
+	if err != nil {
+		return err
+	}
+
+	return u.mfaRepository.EnableMFA(ctx, mfa.UserUUID, step, time.Now().UTC())
+}
+
+// DisableTOTP implements MFAUseCase.
+//
+// A recovery code is accepted in place of a code of the app, so a user who lost
+// the device can turn two-factor authentication off and enroll a new one.
+func (u *mfaUseCase) DisableTOTP(ctx context.Context, principal *auth.Principal, input *schemas.DisableMFAInput) error {
+	user, err := u.activeUser(ctx, principal.UserUUID)
+	if err != nil {
+		return err
+	}
+
+	if !user.CheckPassword(input.Password) {
+		return utils.ErrIncorrectPassword
+	}
+
+	mfa, err := u.mfaRepository.GetMFA(ctx, user.UUID)
+	if err != nil {
+		if err == utils.ErrMFANotEnrolled {
+			return utils.ErrMFANotEnabled
+		}
+		return err
+	}
+
+	if !mfa.IsEnabled() {
+		return utils.ErrMFANotEnabled
+	}
+
+	err = u.useCode(ctx, mfa, input.Code)
+	if err != nil {
+		return err
+	}
+
+	return u.mfaRepository.DeleteMFA(ctx, user.UUID)
+}
+
+// VerifyChallenge implements MFAUseCase.
+//
+// A challenge token is single-use: it is spent by the first attempt, right or wrong,
+// so guessing codes requires the password again for every attempt.
+func (u *mfaUseCase) VerifyChallenge(ctx context.Context, input *schemas.MFAChallengeInput) (*schemas.TokenOutput, error) {
+	claims, err := u.tokenService.ParseMFAToken(input.MFAToken)
+	if err != nil {
+		return nil, utils.ErrInvalidMFAChallenge
+	}
+
+	// Spent in a single insert, so concurrent attempts with the same token cannot both pass
+	first, err := u.blacklist.Spend(ctx, claims.ID, claims.ExpiresAt.Time)
+	if err != nil {
+		return nil, err
+	}
+	if !first {
+		return nil, utils.ErrInvalidMFAChallenge
+	}
+
+	user, err := u.userRepository.GetUserByUUID(ctx, claims.Subject)
+	if err != nil {
+		if err == utils.ErrUserNotFound {
+			return nil, utils.ErrInvalidMFAChallenge
+		}
+		return nil, err
+	}
+
+	if user.IsDeleted {
+		return nil, utils.ErrUserDeleted
+	}
+
+	if user.IsBlocked {
+		return nil, utils.ErrUserBlocked
+	}
+
+	mfa, err := u.mfaRepository.GetMFA(ctx, user.UUID)
+	if err != nil {
+		if err == utils.ErrMFANotEnrolled {
+			return nil, utils.ErrInvalidMFAChallenge
+		}
+		return nil, err
+	}
+
+	if !mfa.IsEnabled() {
+		return nil, utils.ErrInvalidMFAChallenge
+	}
+
+	err = u.useCode(ctx, mfa, input.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := u.sessions.start(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.userRepository.UpdateLastLogin(ctx, user.UUID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// GenerateRecoveryCodes implements MFAUseCase.
+//
+// The codes are only returned here; the database keeps their bcrypt hashes.
+func (u *mfaUseCase) GenerateRecoveryCodes(
+	ctx context.Context, principal *auth.Principal, input *schemas.RecoveryCodesInput,
+) (*schemas.RecoveryCodesOutput, error) {
+	user, err := u.activeUser(ctx, principal.UserUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.CheckPassword(input.Password) {
+		return nil, utils.ErrIncorrectPassword
+	}
+	input.Password = ""
+
+	mfa, err := u.mfaRepository.GetMFA(ctx, user.UUID)
+	if err != nil {
+		if err == utils.ErrMFANotEnrolled {
+			return nil, utils.ErrMFANotEnabled
+		}
+		return nil, err
+	}
+
+	if !mfa.IsEnabled() {
+		return nil, utils.ErrMFANotEnabled
+	}
+
+	codes, err := otpapp.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, utils.ErrGenerateRecoveryCodes
+	}
+
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hash, err := bcrypt.GenerateFromPassword([]byte(otpapp.NormalizeRecoveryCode(code)), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, utils.ErrGenerateRecoveryCodes
+		}
+		hashes = append(hashes, string(hash))
+	}
+
+	err = u.mfaRepository.ReplaceRecoveryCodes(ctx, user.UUID, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemas.RecoveryCodesOutput{
+		Codes: codes,
+	}, nil
+}
+
+// GetSecurityProfile implements MFAUseCase.
+func (u *mfaUseCase) GetSecurityProfile(ctx context.Context, principal *auth.Principal) (*schemas.SecurityProfileOutput, error) {
+	output := &schemas.SecurityProfileOutput{}
+
+	mfa, err := u.mfaRepository.GetMFA(ctx, principal.UserUUID)
+	if err != nil {
+		if err == utils.ErrMFANotEnrolled {
+			return output, nil
+		}
+		return nil, err
+	}
+
+	if !mfa.IsEnabled() {
+		return output, nil
+	}
+
+	remaining, err := u.mfaRepository.CountUnusedRecoveryCodes(ctx, principal.UserUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	output.MFAEnabled = true
+	output.MFAEnabledAt = &mfa.EnabledAt
+	output.RecoveryCodesRemaining = remaining
+
+	return output, nil
+}
+
+// activeUser gets the user, which must not be deleted or blocked
+func (u *mfaUseCase) activeUser(ctx context.Context, userUUID string) (*entity.User, error) {
+	user, err := u.userRepository.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsDeleted {
+		return nil, utils.ErrUserNotFound
+	}
+
+	if user.IsBlocked {
+		return nil, utils.ErrUserBlocked
+	}
+
+	return user, nil
+}
+
+// useCode checks a code of the app against an enabled enrollment and spends its time step,
+// or spends the matching recovery code when the code is not a TOTP code
+func (u *mfaUseCase) useCode(ctx context.Context, mfa *entity.UserMFA, code string) error {
+	if validator.ValidateOTP(code) != nil {
+		return u.useRecoveryCode(ctx, mfa.UserUUID, code)
+	}
+
+	step, err := u.matchCode(mfa, code)
+	if err != nil {
+		return err
+	}
+
+	return u.mfaRepository.UseMFAStep(ctx, mfa.UserUUID, step)
+}
+
+// useRecoveryCode spends the unused recovery code of the user matching the code
+func (u *mfaUseCase) useRecoveryCode(ctx context.Context, userUUID, code string) error {
+	code = otpapp.NormalizeRecoveryCode(code)
+	if code == "" {
+		return utils.ErrInvalidMFACode
+	}
+
+	codes, err := u.mfaRepository.ListUnusedRecoveryCodes(ctx, userUUID)
+	if err != nil {
+		return err
+	}
+
+	for _, recoveryCode := range codes {
+		if bcrypt.CompareHashAndPassword([]byte(recoveryCode.CodeHash), []byte(code)) == nil {
+			return u.mfaRepository.UseRecoveryCode(ctx, recoveryCode.UUID)
+		}
+	}
+
+	return utils.ErrInvalidMFACode
+}
+
+// matchCode checks the code against the secret of the enrollment and returns its time step
+func (u *mfaUseCase) matchCode(mfa *entity.UserMFA, code string) (int64, error) {
+	if validator.ValidateOTP(code) != nil {
+		return 0, utils.ErrInvalidMFACode
+	}
+
+	secret, err := u.cipher.Decrypt(mfa.SecretEncrypted, mfa.UserUUID)
+	if err != nil {
+		return 0, err
+	}
+
+	step, ok := u.totp.Validate(code, secret, time.Now().UTC())
+	if !ok {
+		return 0, utils.ErrInvalidMFACode
+	}
+
+	return step, nil
+}
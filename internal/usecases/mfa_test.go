@@ -0,0 +1,147 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edutav/licentia-usoris/infrastructure/auth"
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+	"github.com/edutav/licentia-usoris/internal/presentation/schemas"
+	"github.com/edutav/licentia-usoris/internal/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Recovery codes of the test user, each accepted once
+var testRecoveryCodes = []string{"aaaa-1111", "bbbb-2222", "cccc-3333", "dddd-4444"}
+
+// newTestMFAUseCase creates an MFA use case for a user with MFA enabled and the test recovery codes
+func newTestMFAUseCase(t *testing.T) (*mfaUseCase, *auth.JWTService) {
+	t.Helper()
+
+	tokenService, err := auth.NewJWTService("test-secret", "test", nil, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mfaRepo := &fakeMFARepository{
+		enrollments: map[string]*entity.UserMFA{
+			"user-1": {UserUUID: "user-1", EnabledAt: time.Now().UTC()},
+		},
+	}
+	for i, code := range testRecoveryCodes {
+		hash, err := bcrypt.GenerateFromPassword([]byte(code[:4]+code[5:]), bcrypt.MinCost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mfaRepo.recoveryCodes = append(mfaRepo.recoveryCodes, &entity.RecoveryCode{
+			UUID:     fmt.Sprintf("code-%d", i),
+			UserUUID: "user-1",
+			CodeHash: string(hash),
+		})
+	}
+
+	u := NewMFAUseCase(
+		newFakeUserRepository(&entity.User{UUID: "user-1", Email: "user@example.com", IsEmailVerified: true}),
+		mfaRepo,
+		newFakeRefreshTokenRepository(),
+		nil,
+		nil,
+		tokenService,
+		newFakeBlacklist(),
+		time.Hour,
+	).(*mfaUseCase)
+
+	return u, tokenService
+}
+
+func TestVerifyChallengeSingleUse(t *testing.T) {
+	tests := []struct {
+		name string
+		// codes are tried in order with the same challenge
+		codes   []string
+		wantErr []error
+	}{
+		{
+			name:    "first use completes the login",
+			codes:   []string{testRecoveryCodes[0]},
+			wantErr: []error{nil},
+		},
+		{
+			name:    "second use is refused",
+			codes:   []string{testRecoveryCodes[0], testRecoveryCodes[1]},
+			wantErr: []error{nil, utils.ErrInvalidMFAChallenge},
+		},
+		{
+			name:    "wrong code spends the challenge",
+			codes:   []string{"zzzz-9999", testRecoveryCodes[0]},
+			wantErr: []error{utils.ErrInvalidMFACode, utils.ErrInvalidMFAChallenge},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, tokenService := newTestMFAUseCase(t)
+
+			challenge, err := tokenService.GenerateMFAToken("user-1", time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i, code := range tt.codes {
+				output, err := u.VerifyChallenge(context.Background(), &schemas.MFAChallengeInput{
+					MFAToken: challenge.Value,
+					Code:     code,
+				})
+				if !errors.Is(err, tt.wantErr[i]) {
+					t.Fatalf("attempt %d: VerifyChallenge() error = %v, want %v", i+1, err, tt.wantErr[i])
+				}
+				if err == nil && output.AccessToken == "" {
+					t.Errorf("attempt %d: no access token issued", i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyChallengeConcurrentUses(t *testing.T) {
+	u, tokenService := newTestMFAUseCase(t)
+
+	challenge, err := tokenService.GenerateMFAToken("user-1", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every attempt has a valid code of its own, only the challenge is shared
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		successes int
+	)
+	for _, code := range testRecoveryCodes {
+		wg.Add(1)
+		go func(code string) {
+			defer wg.Done()
+
+			_, err := u.VerifyChallenge(context.Background(), &schemas.MFAChallengeInput{
+				MFAToken: challenge.Value,
+				Code:     code,
+			})
+			if err == nil {
+				mu.Lock()
+				successes++
+				mu.Unlock()
+			} else if !errors.Is(err, utils.ErrInvalidMFAChallenge) {
+				t.Errorf("VerifyChallenge() error = %v", err)
+			}
+		}(code)
+	}
+	wg.Wait()
+
+	if successes != 1 {
+		t.Errorf("%d concurrent uses of the challenge succeeded, want 1", successes)
+	}
+}
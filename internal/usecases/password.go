@@ -0,0 +1,274 @@
+package usecases
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/edutav/licentia-usoris/infrastructure/auth"
+	"github.com/edutav/licentia-usoris/infrastructure/email"
+	"github.com/edutav/licentia-usoris/infrastructure/logger"
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+	"github.com/edutav/licentia-usoris/internal/domain/reporitory"
+	"github.com/edutav/licentia-usoris/internal/presentation/schemas"
+	"github.com/edutav/licentia-usoris/internal/usecases/validator"
+	"github.com/edutav/licentia-usoris/internal/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type PasswordUseCase interface {
+	// Send a password reset link to the user, if it exists
+	ForgotPassword(ctx context.Context, input *schemas.ForgotPasswordInput) error
+
+	// Reset the password with a password reset token and end every session of the user
+	ResetPassword(ctx context.Context, input *schemas.ResetPasswordInput) error
+
+	// Change the password of the user, ending every other session and starting a new one
+	ChangePassword(ctx context.Context, principal *auth.Principal, input *schemas.ChangePasswordInput) (*schemas.TokenOutput, error)
+}
+
+// Number of previous passwords a new password cannot match, besides the current one
+const passwordHistorySize = 5
+
+type passwordUseCase struct {
+	userRepository          reporitory.UserRepository
+	passwordResetRepository reporitory.PasswordResetRepository
+	emailSender             email.EmailSender
+	sessions                *sessionRevoker
+	issuer                  *sessionIssuer
+	resetTokenTTL           time.Duration
+	resetURL                string
+	validateUserPassword    validator.ValidatePasswordFunc
+}
+
+// NewPasswordUseCase creates a new password use case
+func NewPasswordUseCase(
+	userRepository reporitory.UserRepository,
+	passwordResetRepository reporitory.PasswordResetRepository,
+	refreshTokenRepository reporitory.RefreshTokenRepository,
+	emailSender email.EmailSender,
+	tokenService auth.TokenService,
+	blacklist auth.TokenBlacklist,
+	refreshTokenTTL time.Duration,
+	resetTokenTTL time.Duration,
+	resetURL string,
+	validatePassword validator.ValidatePasswordFunc,
+) PasswordUseCase {
+	if validatePassword == nil {
+		validatePassword = validator.ValidateUserPassword
+	}
+	if refreshTokenTTL <= 0 {
+		refreshTokenTTL = 30 * 24 * time.Hour
+	}
+	if resetTokenTTL <= 0 {
+		resetTokenTTL = 30 * time.Minute
+	}
+	return &passwordUseCase{
+		userRepository:          userRepository,
+		passwordResetRepository: passwordResetRepository,
+		emailSender:             emailSender,
+		sessions: &sessionRevoker{
+			refreshTokenRepository: refreshTokenRepository,
+			tokenService:           tokenService,
+			blacklist:              blacklist,
+		},
+		issuer: &sessionIssuer{
+			userRepository:         userRepository,
+			refreshTokenRepository: refreshTokenRepository,
+			tokenService:           tokenService,
+			refreshTokenTTL:        refreshTokenTTL,
+		},
+		resetTokenTTL:        resetTokenTTL,
+		resetURL:             resetURL,
+		validateUserPassword: validatePassword,
+	}
+}
+
+// ForgotPassword implements PasswordUseCase.
+//
+// It only fails on invalid input, so the caller cannot tell whether the email
+// belongs to an account. Other errors are logged.
+func (u *passwordUseCase) ForgotPassword(ctx context.Context, input *schemas.ForgotPasswordInput) error {
+	user, err := u.userRepository.GetUserByEmail(ctx, input.Email)
+	if err != nil {
+		if err != utils.ErrUserNotFound {
+			logger.FromContext(ctx).WithError(err).Error("Error getting user for password reset")
+		}
+		return nil
+	}
+
+	if user.IsDeleted || user.IsBlocked {
+		return nil
+	}
+
+	value, hash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error generating password reset token")
+		return nil
+	}
+
+	now := time.Now().UTC()
+	token := &entity.PasswordResetToken{
+		UserUUID:  user.UUID,
+		TokenHash: hash,
+		ExpiresAt: now.Add(u.resetTokenTTL),
+		CreatedAt: now,
+	}
+
+	err = u.passwordResetRepository.CreatePasswordResetToken(ctx, token)
+	if err != nil {
+		return nil
+	}
+
+	// Sent in the background, so the response time does not tell known emails apart
+	link := u.resetLink(ctx, value)
+	u.emailSender.Background(ctx, func(ctx context.Context) error {
+		return u.emailSender.SendPasswordReset(ctx, user.Email, link)
+	})
+
+	return nil
+}
+
+// ResetPassword implements PasswordUseCase.
+func (u *passwordUseCase) ResetPassword(ctx context.Context, input *schemas.ResetPasswordInput) error {
+	err := u.validateUserPassword(input.Password)
+	if err != nil {
+		return err
+	}
+
+	token, err := u.passwordResetRepository.GetPasswordResetTokenByHash(ctx, auth.HashOpaqueToken(input.Token))
+	if err != nil {
+		return err
+	}
+
+	if token.IsUsed() || token.IsExpired() {
+		return utils.ErrInvalidPasswordResetToken
+	}
+
+	user, err := u.userRepository.GetUserByUUID(ctx, token.UserUUID)
+	if err != nil {
+		if err == utils.ErrUserNotFound {
+			return utils.ErrInvalidPasswordResetToken
+		}
+		return err
+	}
+
+	err = u.checkPasswordReuse(ctx, user, input.Password)
+	if err != nil {
+		return err
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return utils.ErrHashingPassword
+	}
+	input.Password = ""
+
+	err = u.passwordResetRepository.ResetPassword(ctx, token, string(passwordHash), time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	return u.sessions.revokeAll(ctx, token.UserUUID)
+}
+
+// ChangePassword implements PasswordUseCase.
+//
+// Every session of the user is revoked, including the one of the caller, which
+// gets a new pair of tokens in return.
+func (u *passwordUseCase) ChangePassword(
+	ctx context.Context, principal *auth.Principal, input *schemas.ChangePasswordInput,
+) (*schemas.TokenOutput, error) {
+	user, err := u.userRepository.GetUserByUUID(ctx, principal.UserUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsDeleted {
+		return nil, utils.ErrUserNotFound
+	}
+
+	if user.IsBlocked {
+		return nil, utils.ErrUserBlocked
+	}
+
+	if !user.CheckPassword(input.CurrentPassword) {
+		return nil, utils.ErrIncorrectPassword
+	}
+	input.CurrentPassword = ""
+
+	err = u.validateUserPassword(input.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.checkPasswordReuse(ctx, user, input.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(input.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, utils.ErrHashingPassword
+	}
+	input.NewPassword = ""
+
+	err = u.userRepository.UpdatePassword(ctx, user.UUID, string(passwordHash), time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.sessions.revokeAll(ctx, user.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := u.issuer.restart(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	// The email sender logs its own failures
+	_ = u.emailSender.SendPasswordChanged(ctx, user.Email)
+
+	return output, nil
+}
+
+// checkPasswordReuse rejects a password matching the current or a recent password of the user
+func (u *passwordUseCase) checkPasswordReuse(ctx context.Context, user *entity.User, password string) error {
+	if user.CheckPassword(password) {
+		return utils.ErrPasswordReused
+	}
+
+	hashes, err := u.userRepository.GetPasswordHistory(ctx, user.UUID, passwordHistorySize)
+	if err != nil {
+		return err
+	}
+
+	for _, hash := range hashes {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
+			return utils.ErrPasswordReused
+		}
+	}
+
+	return nil
+}
+
+// resetLink builds the link sent to the user, or the bare token when no URL is configured
+func (u *passwordUseCase) resetLink(ctx context.Context, token string) string {
+	if u.resetURL == "" {
+		return token
+	}
+
+	link, err := url.Parse(u.resetURL)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Error parsing password reset URL")
+		return token
+	}
+
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return link.String()
+}
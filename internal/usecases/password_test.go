@@ -0,0 +1,111 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edutav/licentia-usoris/infrastructure/auth"
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+	"github.com/edutav/licentia-usoris/internal/presentation/schemas"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestChangePasswordRevocationCutoff(t *testing.T) {
+	tokenService, err := auth.NewJWTService("test-secret", "test", nil, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("Old@Secret123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The tokens issued before the change, within the same second or earlier
+	issueBefore := func(t *testing.T) string {
+		token, err := tokenService.GenerateAccessToken("user-1", "user@example.com", nil, nil, time.Time{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token.Value
+	}
+
+	tests := []struct {
+		name        string
+		token       func(t *testing.T, before string, output *schemas.TokenOutput) string
+		wantRevoked bool
+	}{
+		{
+			name:        "token issued before the change",
+			token:       func(t *testing.T, before string, output *schemas.TokenOutput) string { return before },
+			wantRevoked: true,
+		},
+		{
+			name:        "token of the new session",
+			token:       func(t *testing.T, before string, output *schemas.TokenOutput) string { return output.AccessToken },
+			wantRevoked: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newFakeUserRepository(&entity.User{
+				UUID:            "user-1",
+				Email:           "user@example.com",
+				PasswordHash:    string(hash),
+				IsEmailVerified: true,
+			})
+			blacklist := newFakeBlacklist()
+
+			u := NewPasswordUseCase(
+				users, nil, newFakeRefreshTokenRepository(), &fakeEmailSender{},
+				tokenService, blacklist, time.Hour, 0, "", nil,
+			)
+
+			before := issueBefore(t)
+
+			output, err := u.ChangePassword(context.Background(), &auth.Principal{UserUUID: "user-1"}, &schemas.ChangePasswordInput{
+				CurrentPassword: "Old@Secret123",
+				NewPassword:     "New@Secret123",
+			})
+			if err != nil {
+				t.Fatalf("ChangePassword() error = %v", err)
+			}
+
+			claims, err := tokenService.ParseAccessToken(tt.token(t, before, output))
+			if err != nil {
+				t.Fatalf("ParseAccessToken() error = %v", err)
+			}
+
+			revoked, err := blacklist.IsUserRevoked(context.Background(), claims.Subject, claims.IssuedAt.Time)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if revoked != tt.wantRevoked {
+				t.Errorf("revoked = %v, want %v (iat %s, revoked before %s)",
+					revoked, tt.wantRevoked, claims.IssuedAt.Time, blacklist.revocations["user-1"])
+			}
+		})
+	}
+}
+
+func TestRevocationCutoff(t *testing.T) {
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"within a second", time.Date(2026, 1, 1, 10, 0, 0, 400_000_000, time.UTC), time.Date(2026, 1, 1, 10, 0, 1, 0, time.UTC)},
+		{"on a whole second", time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC), time.Date(2026, 1, 1, 10, 0, 1, 0, time.UTC)},
+		{"end of a second", time.Date(2026, 1, 1, 10, 0, 0, 999_999_999, time.UTC), time.Date(2026, 1, 1, 10, 0, 1, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := revocationCutoff(tt.now); !got.Equal(tt.want) {
+				t.Errorf("revocationCutoff() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package usecases
+
+import (
+	"context"
+	"time"
+
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+	"github.com/edutav/licentia-usoris/internal/domain/reporitory"
+	"github.com/edutav/licentia-usoris/internal/presentation/schemas"
+	"github.com/edutav/licentia-usoris/internal/usecases/validator"
+)
+
+type PermissionUseCase interface {
+	// Create permission
+	CreatePermission(ctx context.Context, input *schemas.PermissionInput) (*schemas.PermissionOutput, error)
+
+	// List permissions
+	ListPermissions(ctx context.Context) ([]*schemas.PermissionOutput, error)
+
+	// Get permission
+	GetPermission(ctx context.Context, permissionUUID string) (*schemas.PermissionOutput, error)
+
+	// Update permission
+	UpdatePermission(ctx context.Context, permissionUUID string, input *schemas.PermissionInput) (*schemas.PermissionOutput, error)
+
+	// Delete permission
+	DeletePermission(ctx context.Context, permissionUUID string) error
+}
+
+type permissionUseCase struct {
+	permissionRepository reporitory.PermissionRepository
+}
+
+// NewPermissionUseCase creates a new permission use case
+func NewPermissionUseCase(permissionRepository reporitory.PermissionRepository) PermissionUseCase {
+	return &permissionUseCase{
+		permissionRepository: permissionRepository,
+	}
+}
+
+// CreatePermission implements PermissionUseCase.
+func (u *permissionUseCase) CreatePermission(ctx context.Context, input *schemas.PermissionInput) (*schemas.PermissionOutput, error) {
+	err := validator.ValidatePermissionName(input.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	permission := &entity.Permission{
+		Name:        input.Name,
+		Description: input.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	err = u.permissionRepository.CreatePermission(ctx, permission)
+	if err != nil {
+		return nil, err
+	}
+
+	return schemas.NewPermissionOutput(permission), nil
+}
+
+// ListPermissions implements PermissionUseCase.
+func (u *permissionUseCase) ListPermissions(ctx context.Context) ([]*schemas.PermissionOutput, error) {
+	permissions, err := u.permissionRepository.ListPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return schemas.NewPermissionOutputs(permissions), nil
+}
+
+// GetPermission implements PermissionUseCase.
+func (u *permissionUseCase) GetPermission(ctx context.Context, permissionUUID string) (*schemas.PermissionOutput, error) {
+	permission, err := u.permissionRepository.GetPermissionByUUID(ctx, permissionUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return schemas.NewPermissionOutput(permission), nil
+}
+
+// UpdatePermission implements PermissionUseCase.
+func (u *permissionUseCase) UpdatePermission(
+	ctx context.Context, permissionUUID string, input *schemas.PermissionInput,
+) (*schemas.PermissionOutput, error) {
+	err := validator.ValidatePermissionName(input.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	permission, err := u.permissionRepository.GetPermissionByUUID(ctx, permissionUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	permission.Name = input.Name
+	permission.Description = input.Description
+	permission.UpdatedAt = time.Now().UTC()
+
+	err = u.permissionRepository.UpdatePermission(ctx, permission)
+	if err != nil {
+		return nil, err
+	}
+
+	return schemas.NewPermissionOutput(permission), nil
+}
+
+// DeletePermission implements PermissionUseCase.
+func (u *permissionUseCase) DeletePermission(ctx context.Context, permissionUUID string) error {
+	return u.permissionRepository.DeletePermission(ctx, permissionUUID)
+}
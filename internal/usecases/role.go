@@ -0,0 +1,289 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/edutav/licentia-usoris/infrastructure/auth"
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+	"github.com/edutav/licentia-usoris/internal/domain/reporitory"
+	"github.com/edutav/licentia-usoris/internal/presentation/schemas"
+	"github.com/edutav/licentia-usoris/internal/usecases/validator"
+	"github.com/edutav/licentia-usoris/internal/utils"
+)
+
+type RoleUseCase interface {
+	// Create role
+	CreateRole(ctx context.Context, input *schemas.RoleInput) (*schemas.RoleOutput, error)
+
+	// List roles
+	ListRoles(ctx context.Context) ([]*schemas.RoleOutput, error)
+
+	// Get role
+	GetRole(ctx context.Context, roleUUID string) (*schemas.RoleOutput, error)
+
+	// Update role
+	UpdateRole(ctx context.Context, roleUUID string, input *schemas.RoleInput) (*schemas.RoleOutput, error)
+
+	// Delete role
+	DeleteRole(ctx context.Context, roleUUID string) error
+
+	// List the permissions attached to the role
+	ListRolePermissions(ctx context.Context, roleUUID string) ([]*schemas.PermissionOutput, error)
+
+	// Attach permission to role, refusing permissions the principal does not hold
+	AttachPermission(ctx context.Context, principal *auth.Principal, roleUUID, permissionUUID string) error
+
+	// Detach permission from role
+	DetachPermission(ctx context.Context, roleUUID, permissionUUID string) error
+
+	// List the roles assigned to the user
+	ListUserRoles(ctx context.Context, userUUID string) ([]*schemas.RoleOutput, error)
+
+	// Assign role to user, refusing roles that grant permissions the principal does not hold
+	AssignRole(ctx context.Context, principal *auth.Principal, userUUID, roleUUID string) error
+
+	// Revoke role from user
+	RevokeRole(ctx context.Context, userUUID, roleUUID string) error
+}
+
+type roleUseCase struct {
+	roleRepository       reporitory.RoleRepository
+	permissionRepository reporitory.PermissionRepository
+	userRepository       reporitory.UserRepository
+}
+
+// NewRoleUseCase creates a new role use case
+func NewRoleUseCase(
+	roleRepository reporitory.RoleRepository,
+	permissionRepository reporitory.PermissionRepository,
+	userRepository reporitory.UserRepository,
+) RoleUseCase {
+	return &roleUseCase{
+		roleRepository:       roleRepository,
+		permissionRepository: permissionRepository,
+		userRepository:       userRepository,
+	}
+}
+
+// CreateRole implements RoleUseCase.
+func (u *roleUseCase) CreateRole(ctx context.Context, input *schemas.RoleInput) (*schemas.RoleOutput, error) {
+	err := validator.ValidateRoleName(input.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	role := &entity.Role{
+		Name:        input.Name,
+		Description: input.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	err = u.roleRepository.CreateRole(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+
+	return schemas.NewRoleOutput(role), nil
+}
+
+// ListRoles implements RoleUseCase.
+func (u *roleUseCase) ListRoles(ctx context.Context) ([]*schemas.RoleOutput, error) {
+	roles, err := u.roleRepository.ListRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return schemas.NewRoleOutputs(roles), nil
+}
+
+// GetRole implements RoleUseCase.
+func (u *roleUseCase) GetRole(ctx context.Context, roleUUID string) (*schemas.RoleOutput, error) {
+	role, err := u.roleRepository.GetRoleByUUID(ctx, roleUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return schemas.NewRoleOutput(role), nil
+}
+
+// UpdateRole implements RoleUseCase.
+func (u *roleUseCase) UpdateRole(ctx context.Context, roleUUID string, input *schemas.RoleInput) (*schemas.RoleOutput, error) {
+	err := validator.ValidateRoleName(input.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := u.roleRepository.GetRoleByUUID(ctx, roleUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	role.Name = input.Name
+	role.Description = input.Description
+	role.UpdatedAt = time.Now().UTC()
+
+	err = u.roleRepository.UpdateRole(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+
+	return schemas.NewRoleOutput(role), nil
+}
+
+// DeleteRole implements RoleUseCase.
+func (u *roleUseCase) DeleteRole(ctx context.Context, roleUUID string) error {
+	return u.roleRepository.DeleteRole(ctx, roleUUID)
+}
+
+// ListRolePermissions implements RoleUseCase.
+func (u *roleUseCase) ListRolePermissions(ctx context.Context, roleUUID string) ([]*schemas.PermissionOutput, error) {
+	_, err := u.roleRepository.GetRoleByUUID(ctx, roleUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := u.roleRepository.ListRolePermissions(ctx, roleUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return schemas.NewPermissionOutputs(permissions), nil
+}
+
+// AttachPermission implements RoleUseCase.
+func (u *roleUseCase) AttachPermission(ctx context.Context, principal *auth.Principal, roleUUID, permissionUUID string) error {
+	_, err := u.roleRepository.GetRoleByUUID(ctx, roleUUID)
+	if err != nil {
+		return err
+	}
+
+	permission, err := u.permissionRepository.GetPermissionByUUID(ctx, permissionUUID)
+	if err != nil {
+		return err
+	}
+
+	// Attaching to a role the principal holds would otherwise grant them the permission
+	err = checkHeld(ctx, u.userRepository, principal, []string{permission.Name})
+	if err != nil {
+		return err
+	}
+
+	return u.roleRepository.AttachPermission(ctx, roleUUID, permissionUUID)
+}
+
+// DetachPermission implements RoleUseCase.
+func (u *roleUseCase) DetachPermission(ctx context.Context, roleUUID, permissionUUID string) error {
+	return u.roleRepository.DetachPermission(ctx, roleUUID, permissionUUID)
+}
+
+// ListUserRoles implements RoleUseCase.
+func (u *roleUseCase) ListUserRoles(ctx context.Context, userUUID string) ([]*schemas.RoleOutput, error) {
+	_, err := u.userRepository.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := u.roleRepository.ListUserRoles(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return schemas.NewRoleOutputs(roles), nil
+}
+
+// AssignRole implements RoleUseCase.
+func (u *roleUseCase) AssignRole(ctx context.Context, principal *auth.Principal, userUUID, roleUUID string) error {
+	_, err := u.userRepository.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return err
+	}
+
+	_, err = u.roleRepository.GetRoleByUUID(ctx, roleUUID)
+	if err != nil {
+		return err
+	}
+
+	err = checkGrantable(ctx, u.userRepository, u.roleRepository, principal, roleUUID)
+	if err != nil {
+		return err
+	}
+
+	return u.roleRepository.AssignRole(ctx, userUUID, roleUUID)
+}
+
+// RevokeRole implements RoleUseCase.
+func (u *roleUseCase) RevokeRole(ctx context.Context, userUUID, roleUUID string) error {
+	return u.roleRepository.RevokeRole(ctx, userUUID, roleUUID)
+}
+
+// checkGrantable checks that the principal holds every permission of the role,
+// so that nobody can grant more than they have
+func checkGrantable(
+	ctx context.Context,
+	userRepository reporitory.UserRepository,
+	roleRepository reporitory.RoleRepository,
+	principal *auth.Principal,
+	roleUUID string,
+) error {
+	granted, err := roleRepository.ListRolePermissions(ctx, roleUUID)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(granted))
+	for _, permission := range granted {
+		names = append(names, permission.Name)
+	}
+
+	return checkHeld(ctx, userRepository, principal, names)
+}
+
+// checkOutranks checks that the principal holds every permission of the user,
+// so that nobody can manage an account that has more than they have
+func checkOutranks(
+	ctx context.Context,
+	userRepository reporitory.UserRepository,
+	principal *auth.Principal,
+	userUUID string,
+) error {
+	permissions, err := userRepository.GetUserPermissions(ctx, userUUID)
+	if err != nil {
+		return err
+	}
+
+	err = checkHeld(ctx, userRepository, principal, permissions)
+	if errors.Is(err, utils.ErrRoleExceedsGrantor) {
+		return utils.ErrUserExceedsCaller
+	}
+	return err
+}
+
+// checkHeld checks that the principal holds every one of the permissions
+func checkHeld(
+	ctx context.Context,
+	userRepository reporitory.UserRepository,
+	principal *auth.Principal,
+	permissions []string,
+) error {
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	held, err := userRepository.GetUserPermissions(ctx, principal.UserUUID)
+	if err != nil {
+		return err
+	}
+
+	for _, permission := range permissions {
+		if !slices.Contains(held, permission) {
+			return utils.ErrRoleExceedsGrantor
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/edutav/licentia-usoris/infrastructure/auth"
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+	"github.com/edutav/licentia-usoris/internal/utils"
+)
+
+func TestAssignRole(t *testing.T) {
+	tests := []struct {
+		name    string
+		held    []string
+		role    string
+		wantErr error
+	}{
+		{"role within the held permissions", []string{"read:user", "update:user", "update:role"}, "reader", nil},
+		{"role without permissions", []string{"update:user", "update:role"}, "empty", nil},
+		{"role granting a permission not held", []string{"read:user", "update:user", "update:role"}, "admin", utils.ErrRoleExceedsGrantor},
+		{"caller without permissions", nil, "reader", utils.ErrRoleExceedsGrantor},
+		{"unknown role", []string{"read:user", "delete:user"}, "missing", utils.ErrRoleNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newFakeUserRepository(
+				&entity.User{UUID: "caller"},
+				&entity.User{UUID: "target"},
+			)
+			users.permissions["caller"] = tt.held
+
+			roles := newFakeRoleRepository(map[string][]string{
+				"reader": {"read:user"},
+				"empty":  {},
+				"admin":  {"read:user", "delete:user"},
+			})
+
+			u := NewRoleUseCase(roles, nil, users)
+			err := u.AssignRole(context.Background(), &auth.Principal{UserUUID: "caller"}, "target", tt.role)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AssignRole() error = %v, want %v", err, tt.wantErr)
+			}
+
+			assigned := slices.Contains(roles.assigned["target"], tt.role)
+			if assigned != (tt.wantErr == nil) {
+				t.Errorf("role assigned = %v, want %v", assigned, tt.wantErr == nil)
+			}
+		})
+	}
+}
+
+func TestAttachPermission(t *testing.T) {
+	tests := []struct {
+		name       string
+		held       []string
+		role       string
+		permission string
+		wantErr    error
+	}{
+		{"permission held", []string{"update:role", "read:user"}, "reader", "read:user", nil},
+		{"permission not held", []string{"update:role", "read:user"}, "reader", "delete:user", utils.ErrRoleExceedsGrantor},
+		{"permission not held attached to a role of the caller", []string{"update:role"}, "caller-role", "delete:user", utils.ErrRoleExceedsGrantor},
+		{"unknown permission", []string{"update:role"}, "reader", "missing", utils.ErrPermissionNotFound},
+		{"unknown role", []string{"update:role", "read:user"}, "missing", "read:user", utils.ErrRoleNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newFakeUserRepository(&entity.User{UUID: "caller"})
+			users.permissions["caller"] = tt.held
+
+			roles := newFakeRoleRepository(map[string][]string{
+				"reader":      {},
+				"caller-role": {"update:role"},
+			})
+			permissions := &fakePermissionRepository{names: []string{"read:user", "delete:user"}}
+
+			u := NewRoleUseCase(roles, permissions, users)
+			err := u.AttachPermission(context.Background(), &auth.Principal{UserUUID: "caller"}, tt.role, tt.permission)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AttachPermission() error = %v, want %v", err, tt.wantErr)
+			}
+
+			attached := slices.Contains(roles.permissions[tt.role], tt.permission)
+			if attached != (tt.wantErr == nil) {
+				t.Errorf("permission attached = %v, want %v", attached, tt.wantErr == nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package usecases
+
+import (
+	"context"
+	"time"
+
+	"github.com/edutav/licentia-usoris/infrastructure/auth"
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+	"github.com/edutav/licentia-usoris/internal/domain/reporitory"
+	"github.com/edutav/licentia-usoris/internal/presentation/schemas"
+	"github.com/edutav/licentia-usoris/internal/utils"
+)
+
+// sessionRevoker ends every session of a user
+type sessionRevoker struct {
+	refreshTokenRepository reporitory.RefreshTokenRepository
+	tokenService           auth.TokenService
+	blacklist              auth.TokenBlacklist
+}
+
+// revokeAll revokes the refresh tokens of the user and every access token issued
+// to it until now. The revocation is kept while those access tokens may be valid.
+func (s *sessionRevoker) revokeAll(ctx context.Context, userUUID string) error {
+	err := s.refreshTokenRepository.RevokeUserRefreshTokens(ctx, userUUID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+
+	return s.blacklist.RevokeUser(ctx, userUUID, revocationCutoff(now), now.Add(s.tokenService.AccessTokenTTL()+time.Second))
+}
+
+// revocationCutoff is the first issue time a revocation at now does not reach.
+// The iat claim keeps whole seconds, so the tokens issued during the current second are revoked too.
+func revocationCutoff(now time.Time) time.Time {
+	return now.Truncate(time.Second).Add(time.Second)
+}
+
+// sessionIssuer issues the access and refresh tokens of a session
+type sessionIssuer struct {
+	userRepository         reporitory.UserRepository
+	refreshTokenRepository reporitory.RefreshTokenRepository
+	tokenService           auth.TokenService
+	refreshTokenTTL        time.Duration
+}
+
+// refreshToken is a newly generated refresh token and its storage entity
+type refreshToken struct {
+	value  string
+	entity *entity.RefreshToken
+}
+
+// start starts a new session for the user with a new refresh token family
+func (s *sessionIssuer) start(ctx context.Context, user *entity.User) (*schemas.TokenOutput, error) {
+	return s.startIssuedAt(ctx, user, time.Time{})
+}
+
+// restart starts a new session for the user right after its sessions were revoked.
+// Its access token is issued at the revocation cutoff, which the revocation does not reach.
+func (s *sessionIssuer) restart(ctx context.Context, user *entity.User) (*schemas.TokenOutput, error) {
+	return s.startIssuedAt(ctx, user, revocationCutoff(time.Now().UTC()))
+}
+
+// startIssuedAt starts a new session whose access token is issued no earlier than minIssuedAt
+func (s *sessionIssuer) startIssuedAt(ctx context.Context, user *entity.User, minIssuedAt time.Time) (*schemas.TokenOutput, error) {
+	refreshToken, err := s.newRefreshToken(user.UUID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.refreshTokenRepository.CreateRefreshToken(ctx, refreshToken.entity)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(ctx, user, refreshToken.value, minIssuedAt)
+}
+
+// newRefreshToken generates a refresh token for the user in the given family
+func (s *sessionIssuer) newRefreshToken(userUUID, familyID string) (*refreshToken, error) {
+	value, hash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, utils.ErrGenerateRefreshToken
+	}
+
+	now := time.Now().UTC()
+
+	return &refreshToken{
+		value: value,
+		entity: &entity.RefreshToken{
+			UserUUID:  userUUID,
+			FamilyID:  familyID,
+			TokenHash: hash,
+			ExpiresAt: now.Add(s.refreshTokenTTL),
+			CreatedAt: now,
+		},
+	}, nil
+}
+
+// issueTokens generates an access token for the user, issued no earlier than minIssuedAt,
+// and pairs it with the refresh token
+func (s *sessionIssuer) issueTokens(
+	ctx context.Context, user *entity.User, refreshToken string, minIssuedAt time.Time,
+) (*schemas.TokenOutput, error) {
+	roles, err := s.userRepository.GetUserRoles(ctx, user.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := s.userRepository.GetUserPermissions(ctx, user.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := s.tokenService.GenerateAccessToken(user.UUID, user.Email, roles, permissions, minIssuedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemas.TokenOutput{
+		AccessToken:  accessToken.Value,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(time.Until(accessToken.ExpiresAt).Round(time.Second).Seconds()),
+	}, nil
+}
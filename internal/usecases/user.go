@@ -2,10 +2,13 @@ package usecases
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/edutav/licentia-usoris/infrastructure/auth"
 	"github.com/edutav/licentia-usoris/infrastructure/email"
-	otpapp "github.com/edutav/licentia-usoris/infrastructure/otp_app"
+	"github.com/edutav/licentia-usoris/infrastructure/metrics"
+	"github.com/edutav/licentia-usoris/infrastructure/tracing"
 	"github.com/edutav/licentia-usoris/internal/domain/entity"
 	"github.com/edutav/licentia-usoris/internal/domain/reporitory"
 	"github.com/edutav/licentia-usoris/internal/presentation/schemas"
@@ -14,34 +17,95 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// dummyPasswordHash is compared on login attempts for unknown emails.
+// It uses the cost of the stored hashes so that both paths take the same time.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("licentia-usoris-dummy-password"), bcrypt.DefaultCost)
+
 type UserUseCase interface {
 	// Pre-registration new user
 	PreRegisterUser(ctx context.Context, preRegistration *schemas.PreRegistrationInput) error
 
+	// Send a new OTP code for a pending pre-registration
+	ResendOTPCode(ctx context.Context, email string) error
+
 	// Verify OTP code
 	VerifyOTPCode(ctx context.Context, email, code string) error
+
+	// Login user with email and password, or start an MFA challenge when two-factor authentication is enabled
+	Login(ctx context.Context, input *schemas.LoginInput) (*schemas.LoginOutput, error)
+
+	// Exchange a refresh token for a new pair of tokens
+	RefreshToken(ctx context.Context, refreshToken string) (*schemas.TokenOutput, error)
+
+	// Logout user, revoking the access token and the refresh token family
+	Logout(ctx context.Context, principal *auth.Principal, refreshToken string) error
+
+	// Get the profile of the user
+	GetProfile(ctx context.Context, userUUID string) (*schemas.UserOutput, error)
 }
 
+// Time a pre-registration can be verified in
+const preRegistrationTTL = 24 * time.Hour
+
 type userUseCase struct {
-	userRepository       reporitory.UserRepository
-	emailSender          email.EmailSender
-	validateUserPassword validator.ValidatePasswordFunc
+	userRepository         reporitory.UserRepository
+	refreshTokenRepository reporitory.RefreshTokenRepository
+	mfaRepository          reporitory.MFARepository
+	verificationCodes      VerificationCodeUseCase
+	emailSender            email.EmailSender
+	tokenService           auth.TokenService
+	blacklist              auth.TokenBlacklist
+	sessions               *sessionIssuer
+	mfaChallengeTTL        time.Duration
+	validateUserPassword   validator.ValidatePasswordFunc
 }
 
 // NewUserUseCase creates a new user use case
-func NewUserUseCase(userRepository reporitory.UserRepository, emailSender email.EmailSender, validatePassword validator.ValidatePasswordFunc) UserUseCase {
+func NewUserUseCase(
+	userRepository reporitory.UserRepository,
+	refreshTokenRepository reporitory.RefreshTokenRepository,
+	mfaRepository reporitory.MFARepository,
+	verificationCodes VerificationCodeUseCase,
+	emailSender email.EmailSender,
+	tokenService auth.TokenService,
+	blacklist auth.TokenBlacklist,
+	refreshTokenTTL time.Duration,
+	mfaChallengeTTL time.Duration,
+	validatePassword validator.ValidatePasswordFunc,
+) UserUseCase {
 	if validatePassword == nil {
 		validatePassword = validator.ValidateUserPassword
 	}
+	if refreshTokenTTL <= 0 {
+		refreshTokenTTL = 30 * 24 * time.Hour
+	}
+	if mfaChallengeTTL <= 0 {
+		mfaChallengeTTL = 5 * time.Minute
+	}
 	return &userUseCase{
-		userRepository:       userRepository,
-		emailSender:          emailSender,
+		userRepository:         userRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		mfaRepository:          mfaRepository,
+		verificationCodes:      verificationCodes,
+		emailSender:            emailSender,
+		tokenService:           tokenService,
+		blacklist:              blacklist,
+		sessions: &sessionIssuer{
+			userRepository:         userRepository,
+			refreshTokenRepository: refreshTokenRepository,
+			tokenService:           tokenService,
+			refreshTokenTTL:        refreshTokenTTL,
+		},
+		mfaChallengeTTL:      mfaChallengeTTL,
 		validateUserPassword: validatePassword,
 	}
 }
 
 // PreRegisterUser implements UserUseCase.
-func (u *userUseCase) PreRegisterUser(ctx context.Context, preRegistration *schemas.PreRegistrationInput) error {
+func (u *userUseCase) PreRegisterUser(ctx context.Context, preRegistration *schemas.PreRegistrationInput) (err error) {
+	ctx, span := tracing.Start(ctx, "userUseCase.PreRegisterUser")
+	defer func() { tracing.End(span, err) }()
+
 	// Check if the email is already registered
 	existingUser, err := u.userRepository.GetUserByEmail(ctx, preRegistration.Email)
 	if err == nil && existingUser != nil && existingUser.IsEmailVerified {
@@ -50,45 +114,26 @@ func (u *userUseCase) PreRegisterUser(ctx context.Context, preRegistration *sche
 		return err
 	}
 
-	// Validate password
-	err = u.validateUserPassword(preRegistration.Password)
+	// Validate the password and the name, reporting every invalid field
+	err = errors.Join(
+		u.validateUserPassword(preRegistration.Password),
+		validator.ValidateUserName(preRegistration.Name),
+	)
 	if err != nil {
-		switch err {
-		case utils.ErrPasswordTooShort:
-			return utils.ErrPasswordTooShort
-		case utils.ErrPasswordTooLong:
-			return utils.ErrPasswordTooLong
-		case utils.ErrPasswordSecurity:
-			return utils.ErrPasswordSecurity
-		}
+		return err
 	}
 
 	// Password hashing
+	_, hashSpan := tracing.Start(ctx, "bcrypt.GenerateFromPassword")
 	passwordHash, err := bcrypt.GenerateFromPassword([]byte(preRegistration.Password), bcrypt.DefaultCost)
+	tracing.End(hashSpan, err)
 	if err != nil {
 		return utils.ErrHashingPassword
 	}
 	preRegistration.Password = ""
 
-	err = validator.ValidateUserName(preRegistration.Name)
-	if err != nil {
-		switch err {
-		case utils.ErrUserNameTooShort:
-			return utils.ErrUserNameTooShort
-		case utils.ErrUserNameTooLong:
-			return utils.ErrUserNameTooLong
-		case utils.ErrUserNameWithNumericVals:
-			return utils.ErrUserNameWithNumericVals
-		}
-	}
-
-	key, otp, err := otpapp.GenerateOTP()
-	if err != nil {
-		return utils.ErrGenerateOTP
-	}
-
-	// OTP Code expiration 60 minutes
-	expiresAt := time.Now().UTC().Add(time.Minute * 60)
+	// Pre-registration expiration, the verification code expires sooner
+	expiresAt := time.Now().UTC().Add(preRegistrationTTL)
 
 	// Parse date of birth
 	var dob time.Time
@@ -112,50 +157,117 @@ func (u *userUseCase) PreRegisterUser(ctx context.Context, preRegistration *sche
 	preRegistrationEntity := &entity.PreRegistration{
 		Email:        preRegistration.Email,
 		PasswordHash: string(passwordHash),
-		CodeOTP:      key,
 		UserData:     newUser,
 		ExpiresAt:    expiresAt,
 		CreatedAt:    time.Now().UTC(),
 	}
 
-	// Save pre registration to database
+	// Issue the code first, so pre-registering again cannot bypass the resend cooldown
+	otp, err := u.issueOTPCode(ctx, preRegistration.Email)
+	if err != nil {
+		return err
+	}
+
+	// Save pre registration to database, replacing a pending one
 	err = u.userRepository.PreRegisterUser(ctx, preRegistrationEntity)
 	if err != nil {
+		if err == utils.ErrDuplicateEmail {
+			return err
+		}
 		return utils.ErrCreateVericationEntry
 	}
 
 	// Send OTP to user email
-	err = u.emailSender.SendOTP(preRegistration.Email, otp)
+	err = u.emailSender.SendOTP(ctx, preRegistration.Email, otp)
 	if err != nil {
 		return utils.ErrSMTPServerIssue
 	}
 
+	metrics.RegistrationsStarted.Inc()
+
 	return err
 }
 
-// VerifyOTPCode implements UserUseCase.
-func (u *userUseCase) VerifyOTPCode(ctx context.Context, email string, code string) error {
-	// Get user by email
-	userRegistred, err := u.userRepository.GetPreRegisteredByEmailAndOTPCode(ctx, email, code)
+// ResendOTPCode implements UserUseCase.
+func (u *userUseCase) ResendOTPCode(ctx context.Context, email string) (err error) {
+	ctx, span := tracing.Start(ctx, "userUseCase.ResendOTPCode")
+	defer func() { tracing.End(span, err) }()
+
+	userRegistred, err := u.userRepository.GetPreRegistrationByEmail(ctx, email)
 	if err != nil {
-		if err == utils.ErrPreRegistredUserNotFound {
-			return utils.ErrPreRegistredUserNotFound
-		}
+		return err
+	}
+
+	if userRegistred.IsVerified {
+		return utils.ErrOTPAlreadyVerified
 	}
 
-	// Check if the OTP code has expired
+	// An expired pre-registration has to be made again
 	if userRegistred.ExpiresAt.Before(time.Now().UTC()) {
 		return utils.ErrOTPExpired
 	}
 
+	otp, err := u.issueOTPCode(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	err = u.emailSender.SendOTP(ctx, email, otp)
+	if err != nil {
+		return utils.ErrSMTPServerIssue
+	}
+
+	return nil
+}
+
+// issueOTPCode issues a new pre-registration code for the email
+func (u *userUseCase) issueOTPCode(ctx context.Context, email string) (string, error) {
+	otp, err := u.verificationCodes.Issue(ctx, VerificationPurposePreRegistration, email)
+	if err != nil {
+		switch err {
+		case utils.ErrOTPCooldown, utils.ErrOTPDailyLimit, utils.ErrGenerateOTP:
+			return "", err
+		}
+		return "", utils.ErrCreateVericationEntry
+	}
+
+	return otp, nil
+}
+
+// VerifyOTPCode implements UserUseCase.
+func (u *userUseCase) VerifyOTPCode(ctx context.Context, email string, code string) (err error) {
+	ctx, span := tracing.Start(ctx, "userUseCase.VerifyOTPCode")
+	defer func() { tracing.End(span, err) }()
+
+	// Get user by email
+	userRegistred, err := u.userRepository.GetPreRegistrationByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+
 	// Check if user is already verified
 	if userRegistred.IsVerified {
 		return utils.ErrOTPAlreadyVerified
 	}
 
-	// Check if the OTP code is valid
-	if otpapp.ValidateOTP(code, userRegistred.CodeOTP) {
-		return utils.ErrInvalidOTP
+	// Check if the pre-registration has expired
+	if userRegistred.ExpiresAt.Before(time.Now().UTC()) {
+		metrics.OTPFailures.WithLabelValues("expired").Inc()
+		return utils.ErrOTPExpired
+	}
+
+	// Check and consume the OTP code
+	err = u.verificationCodes.Verify(ctx, VerificationPurposePreRegistration, email, code)
+	if err != nil {
+		switch err {
+		case utils.ErrInvalidOTP:
+			metrics.OTPFailures.WithLabelValues("invalid").Inc()
+		case utils.ErrOTPExpired:
+			metrics.OTPFailures.WithLabelValues("expired").Inc()
+		case utils.ErrOTPLocked:
+			metrics.OTPFailures.WithLabelValues("locked").Inc()
+		}
+		return err
 	}
 
 	// Check user existing in database
@@ -191,5 +303,212 @@ func (u *userUseCase) VerifyOTPCode(ctx context.Context, email string, code stri
 		return err
 	}
 
+	metrics.RegistrationsCompleted.Inc()
+
 	return err
 }
+
+// Login implements UserUseCase.
+func (u *userUseCase) Login(ctx context.Context, input *schemas.LoginInput) (*schemas.LoginOutput, error) {
+	ctx, span := tracing.Start(ctx, "userUseCase.Login")
+	output, err := u.login(ctx, input)
+	tracing.End(span, err)
+
+	switch {
+	case err != nil:
+		metrics.Logins.WithLabelValues(metrics.ResultFailure).Inc()
+	case output.MFARequired:
+		metrics.Logins.WithLabelValues("mfa_challenge").Inc()
+	default:
+		metrics.Logins.WithLabelValues(metrics.ResultSuccess).Inc()
+	}
+
+	return output, err
+}
+
+// login checks the credentials and starts the session or the MFA challenge
+func (u *userUseCase) login(ctx context.Context, input *schemas.LoginInput) (*schemas.LoginOutput, error) {
+	// Get user by email
+	user, err := u.userRepository.GetUserByEmail(ctx, input.Email)
+	if err != nil {
+		if err == utils.ErrUserNotFound {
+			// Spend the same bcrypt time as for a known email, so the response time
+			// does not reveal whether the account exists
+			_, checkSpan := tracing.Start(ctx, "bcrypt.CompareHashAndPassword")
+			bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(input.Password))
+			checkSpan.End()
+			return nil, utils.ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	// Check the password before revealing anything about the account state
+	_, checkSpan := tracing.Start(ctx, "bcrypt.CompareHashAndPassword")
+	passwordMatches := user.CheckPassword(input.Password)
+	checkSpan.End()
+	if !passwordMatches {
+		return nil, utils.ErrInvalidCredentials
+	}
+
+	if user.IsDeleted {
+		return nil, utils.ErrUserDeleted
+	}
+
+	if user.IsBlocked {
+		return nil, utils.ErrUserBlocked
+	}
+
+	if !user.IsEmailVerified {
+		return nil, utils.ErrEmailNotVerified
+	}
+
+	// The session only starts once the MFA challenge is completed
+	mfa, err := u.mfaRepository.GetMFA(ctx, user.UUID)
+	if err != nil && err != utils.ErrMFANotEnrolled {
+		return nil, err
+	}
+
+	if mfa != nil && mfa.IsEnabled() {
+		challenge, err := u.tokenService.GenerateMFAToken(user.UUID, u.mfaChallengeTTL)
+		if err != nil {
+			return nil, err
+		}
+
+		return &schemas.LoginOutput{
+			MFARequired:  true,
+			MFAToken:     challenge.Value,
+			MFAExpiresIn: int64(time.Until(challenge.ExpiresAt).Round(time.Second).Seconds()),
+		}, nil
+	}
+
+	output, err := u.sessions.start(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.userRepository.UpdateLastLogin(ctx, user.UUID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemas.LoginOutput{
+		TokenOutput: output,
+	}, nil
+}
+
+// RefreshToken implements UserUseCase.
+func (u *userUseCase) RefreshToken(ctx context.Context, refreshToken string) (output *schemas.TokenOutput, err error) {
+	ctx, span := tracing.Start(ctx, "userUseCase.RefreshToken")
+	defer func() { tracing.End(span, err) }()
+
+	current, err := u.refreshTokenRepository.GetRefreshTokenByHash(ctx, auth.HashOpaqueToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+
+	if current.IsRevoked() {
+		return nil, utils.ErrInvalidRefreshToken
+	}
+
+	// A token that was already rotated is being replayed, so the whole family is compromised
+	if current.IsRotated() {
+		err = u.refreshTokenRepository.RevokeRefreshTokenFamily(ctx, current.FamilyID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, utils.ErrRefreshTokenReused
+	}
+
+	if current.IsExpired() {
+		return nil, utils.ErrInvalidRefreshToken
+	}
+
+	user, err := u.userRepository.GetUserByUUID(ctx, current.UserUUID)
+	if err != nil {
+		if err == utils.ErrUserNotFound {
+			return nil, utils.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	if user.IsDeleted {
+		return nil, utils.ErrUserDeleted
+	}
+
+	if user.IsBlocked {
+		return nil, utils.ErrUserBlocked
+	}
+
+	next, err := u.sessions.newRefreshToken(user.UUID, current.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.refreshTokenRepository.RotateRefreshToken(ctx, current, next.entity)
+	if err != nil {
+		// Lost the race against another use of the same token
+		if err == utils.ErrRefreshTokenReused {
+			if err := u.refreshTokenRepository.RevokeRefreshTokenFamily(ctx, current.FamilyID); err != nil {
+				return nil, err
+			}
+			return nil, utils.ErrRefreshTokenReused
+		}
+		return nil, err
+	}
+
+	return u.sessions.issueTokens(ctx, user, next.value, time.Time{})
+}
+
+// Logout implements UserUseCase.
+func (u *userUseCase) Logout(ctx context.Context, principal *auth.Principal, refreshToken string) (err error) {
+	ctx, span := tracing.Start(ctx, "userUseCase.Logout")
+	defer func() { tracing.End(span, err) }()
+
+	// Keep the token blacklisted until it would have expired anyway
+	err = u.blacklist.Add(ctx, principal.TokenID, principal.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	current, err := u.refreshTokenRepository.GetRefreshTokenByHash(ctx, auth.HashOpaqueToken(refreshToken))
+	if err != nil {
+		// An unknown refresh token has nothing left to revoke
+		if err == utils.ErrInvalidRefreshToken {
+			return nil
+		}
+		return err
+	}
+
+	// Only the owner of the session can end it
+	if current.UserUUID != principal.UserUUID {
+		return nil
+	}
+
+	return u.refreshTokenRepository.RevokeRefreshTokenFamily(ctx, current.FamilyID)
+}
+
+// GetProfile implements UserUseCase.
+func (u *userUseCase) GetProfile(ctx context.Context, userUUID string) (output *schemas.UserOutput, err error) {
+	ctx, span := tracing.Start(ctx, "userUseCase.GetProfile")
+	defer func() { tracing.End(span, err) }()
+
+	user, err := u.userRepository.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsDeleted {
+		return nil, utils.ErrUserNotFound
+	}
+
+	roles, err := u.userRepository.GetUserRoles(ctx, user.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return schemas.NewUserOutput(user, roles), nil
+}
@@ -0,0 +1,293 @@
+package usecases
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/edutav/licentia-usoris/infrastructure/auth"
+	"github.com/edutav/licentia-usoris/internal/domain/reporitory"
+	"github.com/edutav/licentia-usoris/internal/presentation/schemas"
+	"github.com/edutav/licentia-usoris/internal/usecases/validator"
+	"github.com/edutav/licentia-usoris/internal/utils"
+	"github.com/edutav/licentia-usoris/internal/utils/helpers"
+)
+
+// Most users a listing may skip, since the database reads every skipped row
+const maxUserListOffset = 10000
+
+type UserAdminUseCase interface {
+	// List the users matching the filter and count them
+	ListUsers(ctx context.Context, input *schemas.UserFilterInput) ([]*schemas.UserOutput, int, error)
+
+	// Get user
+	GetUser(ctx context.Context, userUUID string) (*schemas.UserOutput, error)
+
+	// Update user, ending its sessions when the email changes.
+	// Like the methods below, it refuses users holding permissions the principal does not hold.
+	UpdateUser(ctx context.Context, principal *auth.Principal, userUUID string, input *schemas.UpdateUserInput) (*schemas.UserOutput, error)
+
+	// Soft-delete user and end its sessions
+	DeleteUser(ctx context.Context, principal *auth.Principal, userUUID string) error
+
+	// Restore a soft-deleted user
+	RestoreUser(ctx context.Context, principal *auth.Principal, userUUID string) error
+
+	// Block user with a reason and end its sessions
+	BlockUser(ctx context.Context, principal *auth.Principal, userUUID string, input *schemas.BlockUserInput) error
+
+	// Unblock user
+	UnblockUser(ctx context.Context, principal *auth.Principal, userUUID string) error
+}
+
+type userAdminUseCase struct {
+	userRepository reporitory.UserRepository
+	sessions       *sessionRevoker
+}
+
+// NewUserAdminUseCase creates a new use case for administering users
+func NewUserAdminUseCase(
+	userRepository reporitory.UserRepository,
+	refreshTokenRepository reporitory.RefreshTokenRepository,
+	tokenService auth.TokenService,
+	blacklist auth.TokenBlacklist,
+) UserAdminUseCase {
+	return &userAdminUseCase{
+		userRepository: userRepository,
+		sessions: &sessionRevoker{
+			refreshTokenRepository: refreshTokenRepository,
+			tokenService:           tokenService,
+			blacklist:              blacklist,
+		},
+	}
+}
+
+// ListUsers implements UserAdminUseCase.
+func (u *userAdminUseCase) ListUsers(ctx context.Context, input *schemas.UserFilterInput) ([]*schemas.UserOutput, int, error) {
+	if input.Sort != "" && !slices.Contains(reporitory.UserSortFields, input.Sort) {
+		return nil, 0, utils.ErrInvalidSortField
+	}
+
+	if input.Order != "" && input.Order != "asc" && input.Order != "desc" {
+		return nil, 0, utils.ErrInvalidSortOrder
+	}
+
+	// Compared before multiplying, so a huge page cannot overflow the offset
+	if input.Page > maxUserListOffset/input.PageSize+1 {
+		return nil, 0, utils.ErrPageOutOfRange
+	}
+
+	filter := reporitory.UserFilter{
+		Email:           input.Email,
+		Name:            input.Name,
+		IsBlocked:       input.IsBlocked,
+		IsDeleted:       input.IsDeleted,
+		IsEmailVerified: input.IsEmailVerified,
+		SortBy:          input.Sort,
+		SortDesc:        input.Order == "desc",
+		Limit:           input.PageSize,
+		Offset:          (input.Page - 1) * input.PageSize,
+	}
+
+	total, err := u.userRepository.CountUsers(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	users, err := u.userRepository.ListUsers(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	outputs := make([]*schemas.UserOutput, 0, len(users))
+	for _, user := range users {
+		outputs = append(outputs, schemas.NewUserOutput(user, nil))
+	}
+
+	return outputs, total, nil
+}
+
+// GetUser implements UserAdminUseCase.
+func (u *userAdminUseCase) GetUser(ctx context.Context, userUUID string) (*schemas.UserOutput, error) {
+	user, err := u.userRepository.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := u.userRepository.GetUserRoles(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return schemas.NewUserOutput(user, roles), nil
+}
+
+// UpdateUser implements UserAdminUseCase.
+// Only the fields given in the input are written, so concurrent updates of other fields are kept.
+func (u *userAdminUseCase) UpdateUser(ctx context.Context, principal *auth.Principal, userUUID string, input *schemas.UpdateUserInput) (*schemas.UserOutput, error) {
+	user, err := u.userRepository.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkOutranks(ctx, u.userRepository, principal, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	update := reporitory.UserUpdate{
+		Name:            input.Name,
+		PhoneNumber:     input.PhoneNumber,
+		IsEmailVerified: input.IsEmailVerified,
+		UpdatedAt:       time.Now().UTC(),
+	}
+
+	if input.Name != nil {
+		err = validator.ValidateUserName(*input.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if input.Email != nil && *input.Email != user.Email {
+		err = helpers.ValidateEmail(*input.Email)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = u.userRepository.GetUserByEmail(ctx, *input.Email)
+		if err == nil {
+			return nil, utils.ErrDuplicateEmail
+		} else if err != utils.ErrUserNotFound {
+			return nil, err
+		}
+		update.Email = input.Email
+
+		// The new address is not verified, unless the input says so
+		if input.IsEmailVerified == nil {
+			verified := false
+			update.IsEmailVerified = &verified
+		}
+	}
+
+	if input.DateOfBirth != nil {
+		dob := time.Time{}
+		if *input.DateOfBirth != "" {
+			dob, err = time.Parse("2006-01-02", *input.DateOfBirth)
+			if err != nil {
+				return nil, utils.ErrDOBFormat
+			}
+		}
+		update.DOB = &dob
+	}
+
+	if input.PhoneNumber != nil && *input.PhoneNumber != "" {
+		err = helpers.ValidatePhoneNumber(*input.PhoneNumber)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = u.userRepository.UpdateUser(ctx, userUUID, update)
+	if err != nil {
+		return nil, err
+	}
+
+	// The sessions were opened for the old address
+	if update.Email != nil {
+		err = u.sessions.revokeAll(ctx, userUUID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return u.GetUser(ctx, userUUID)
+}
+
+// DeleteUser implements UserAdminUseCase.
+func (u *userAdminUseCase) DeleteUser(ctx context.Context, principal *auth.Principal, userUUID string) error {
+	_, err := u.userRepository.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return err
+	}
+
+	err = checkOutranks(ctx, u.userRepository, principal, userUUID)
+	if err != nil {
+		return err
+	}
+
+	err = u.userRepository.DeleteUser(ctx, userUUID, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	return u.sessions.revokeAll(ctx, userUUID)
+}
+
+// RestoreUser implements UserAdminUseCase.
+func (u *userAdminUseCase) RestoreUser(ctx context.Context, principal *auth.Principal, userUUID string) error {
+	_, err := u.userRepository.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return err
+	}
+
+	err = checkOutranks(ctx, u.userRepository, principal, userUUID)
+	if err != nil {
+		return err
+	}
+
+	err = u.userRepository.RestoreUser(ctx, userUUID, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	return u.sessions.revokeAll(ctx, userUUID)
+}
+
+// BlockUser implements UserAdminUseCase.
+func (u *userAdminUseCase) BlockUser(ctx context.Context, principal *auth.Principal, userUUID string, input *schemas.BlockUserInput) error {
+	if input.Reason == "" {
+		return utils.ErrMissingBlockReason
+	}
+
+	if len(input.Reason) > utils.BlockReasonMaxLength {
+		return utils.ErrBlockReasonTooLong
+	}
+
+	_, err := u.userRepository.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return err
+	}
+
+	err = checkOutranks(ctx, u.userRepository, principal, userUUID)
+	if err != nil {
+		return err
+	}
+
+	err = u.userRepository.BlockUser(ctx, userUUID, input.Reason, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	return u.sessions.revokeAll(ctx, userUUID)
+}
+
+// UnblockUser implements UserAdminUseCase.
+func (u *userAdminUseCase) UnblockUser(ctx context.Context, principal *auth.Principal, userUUID string) error {
+	_, err := u.userRepository.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return err
+	}
+
+	err = checkOutranks(ctx, u.userRepository, principal, userUUID)
+	if err != nil {
+		return err
+	}
+
+	err = u.userRepository.UnblockUser(ctx, userUUID, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	return u.sessions.revokeAll(ctx, userUUID)
+}
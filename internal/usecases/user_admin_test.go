@@ -0,0 +1,156 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/edutav/licentia-usoris/infrastructure/auth"
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+	"github.com/edutav/licentia-usoris/internal/presentation/schemas"
+	"github.com/edutav/licentia-usoris/internal/utils"
+)
+
+// newUserAdminFixture has a caller managing users, a target below them and an admin above them
+func newUserAdminFixture() (UserAdminUseCase, *fakeUserRepository, *fakeBlacklist) {
+	users := newFakeUserRepository(
+		&entity.User{UUID: "caller", Email: "caller@example.com"},
+		&entity.User{UUID: "target", Email: "target@example.com", IsEmailVerified: true},
+		&entity.User{UUID: "admin", Email: "admin@example.com", IsEmailVerified: true},
+		&entity.User{UUID: "blocked-admin", Email: "blocked@example.com", IsBlocked: true},
+	)
+	users.permissions["caller"] = []string{"read:user", "update:user", "delete:user"}
+	users.permissions["target"] = []string{"read:user"}
+	users.permissions["admin"] = []string{"read:user", "update:user", "delete:user", "update:role"}
+	users.permissions["blocked-admin"] = users.permissions["admin"]
+
+	blacklist := newFakeBlacklist()
+	u := NewUserAdminUseCase(users, newFakeRefreshTokenRepository(), &fakeTokenService{}, blacklist)
+	return u, users, blacklist
+}
+
+func TestUpdateUser(t *testing.T) {
+	verified := true
+
+	tests := []struct {
+		name         string
+		user         string
+		input        schemas.UpdateUserInput
+		wantErr      error
+		wantEmail    string
+		wantVerified bool
+		wantRevoked  bool
+	}{
+		{
+			name:         "name of a user below the caller",
+			user:         "target",
+			input:        schemas.UpdateUserInput{Name: ptr("Jane Doe")},
+			wantEmail:    "target@example.com",
+			wantVerified: true,
+		},
+		{
+			name:         "new email is unverified and ends the sessions",
+			user:         "target",
+			input:        schemas.UpdateUserInput{Email: ptr("new@example.com")},
+			wantEmail:    "new@example.com",
+			wantVerified: false,
+			wantRevoked:  true,
+		},
+		{
+			name:         "new email verified explicitly",
+			user:         "target",
+			input:        schemas.UpdateUserInput{Email: ptr("new@example.com"), IsEmailVerified: &verified},
+			wantEmail:    "new@example.com",
+			wantVerified: true,
+			wantRevoked:  true,
+		},
+		{
+			name:         "same email",
+			user:         "target",
+			input:        schemas.UpdateUserInput{Email: ptr("target@example.com")},
+			wantEmail:    "target@example.com",
+			wantVerified: true,
+		},
+		{
+			name:         "email of a user above the caller",
+			user:         "admin",
+			input:        schemas.UpdateUserInput{Email: ptr("taken-over@example.com")},
+			wantErr:      utils.ErrUserExceedsCaller,
+			wantEmail:    "admin@example.com",
+			wantVerified: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, users, blacklist := newUserAdminFixture()
+
+			_, err := u.UpdateUser(context.Background(), &auth.Principal{UserUUID: "caller"}, tt.user, &tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateUser() error = %v, want %v", err, tt.wantErr)
+			}
+
+			user := users.users[tt.user]
+			if user.Email != tt.wantEmail {
+				t.Errorf("email = %q, want %q", user.Email, tt.wantEmail)
+			}
+			if user.IsEmailVerified != tt.wantVerified {
+				t.Errorf("email verified = %v, want %v", user.IsEmailVerified, tt.wantVerified)
+			}
+
+			_, revoked := blacklist.revocations[tt.user]
+			if revoked != tt.wantRevoked {
+				t.Errorf("sessions revoked = %v, want %v", revoked, tt.wantRevoked)
+			}
+		})
+	}
+}
+
+func TestManageUserAboveCaller(t *testing.T) {
+	tests := []struct {
+		name   string
+		user   string
+		manage func(u UserAdminUseCase, principal *auth.Principal, userUUID string) error
+	}{
+		{"delete", "admin", func(u UserAdminUseCase, principal *auth.Principal, userUUID string) error {
+			return u.DeleteUser(context.Background(), principal, userUUID)
+		}},
+		{"block", "admin", func(u UserAdminUseCase, principal *auth.Principal, userUUID string) error {
+			return u.BlockUser(context.Background(), principal, userUUID, &schemas.BlockUserInput{Reason: "spam"})
+		}},
+		{"unblock", "blocked-admin", func(u UserAdminUseCase, principal *auth.Principal, userUUID string) error {
+			return u.UnblockUser(context.Background(), principal, userUUID)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, users, blacklist := newUserAdminFixture()
+			before := *users.users[tt.user]
+
+			err := tt.manage(u, &auth.Principal{UserUUID: "caller"}, tt.user)
+			if !errors.Is(err, utils.ErrUserExceedsCaller) {
+				t.Fatalf("error = %v, want %v", err, utils.ErrUserExceedsCaller)
+			}
+
+			after := users.users[tt.user]
+			if after.IsDeleted != before.IsDeleted || after.IsBlocked != before.IsBlocked {
+				t.Errorf("user changed: deleted %v -> %v, blocked %v -> %v",
+					before.IsDeleted, after.IsDeleted, before.IsBlocked, after.IsBlocked)
+			}
+			if _, revoked := blacklist.revocations[tt.user]; revoked {
+				t.Error("sessions revoked, want kept")
+			}
+
+			// A caller holding every permission of the user may manage it
+			users.permissions["caller"] = users.permissions["admin"]
+			if err := tt.manage(u, &auth.Principal{UserUUID: "caller"}, tt.user); err != nil {
+				t.Errorf("error with every permission of the user = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func ptr(s string) *string {
+	return &s
+}
@@ -0,0 +1,143 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+	"github.com/edutav/licentia-usoris/internal/utils"
+)
+
+func TestRefreshToken(t *testing.T) {
+	tests := []struct {
+		name string
+		// setup stores the refresh tokens of the case and returns the one presented
+		setup   func(t *testing.T, u *userUseCase, repo *fakeRefreshTokenRepository) string
+		wantErr error
+		// check verifies the stored tokens once the refresh is done
+		check func(t *testing.T, repo *fakeRefreshTokenRepository, presented string)
+	}{
+		{
+			name: "rotates a valid token within its family",
+			setup: func(t *testing.T, u *userUseCase, repo *fakeRefreshTokenRepository) string {
+				return startSession(t, u, "user-1")
+			},
+			check: func(t *testing.T, repo *fakeRefreshTokenRepository, presented string) {
+				if !repo.token(presented).IsRotated() {
+					t.Error("presented token was not rotated")
+				}
+			},
+		},
+		{
+			name: "reuse of a rotated token revokes the family",
+			setup: func(t *testing.T, u *userUseCase, repo *fakeRefreshTokenRepository) string {
+				presented := startSession(t, u, "user-1")
+				if _, err := u.RefreshToken(context.Background(), presented); err != nil {
+					t.Fatalf("first refresh: %v", err)
+				}
+				return presented
+			},
+			wantErr: utils.ErrRefreshTokenReused,
+			check: func(t *testing.T, repo *fakeRefreshTokenRepository, presented string) {
+				family := repo.token(presented).FamilyID
+				for _, token := range repo.tokens {
+					if token.FamilyID == family && !token.IsRevoked() {
+						t.Errorf("token %s of the reused family is still active", token.UUID)
+					}
+				}
+			},
+		},
+		{
+			name: "revoked token is refused",
+			setup: func(t *testing.T, u *userUseCase, repo *fakeRefreshTokenRepository) string {
+				presented := startSession(t, u, "user-1")
+				repo.token(presented).RevokedAt = time.Now().UTC()
+				return presented
+			},
+			wantErr: utils.ErrInvalidRefreshToken,
+		},
+		{
+			name: "expired token is refused",
+			setup: func(t *testing.T, u *userUseCase, repo *fakeRefreshTokenRepository) string {
+				presented := startSession(t, u, "user-1")
+				repo.token(presented).ExpiresAt = time.Now().UTC().Add(-time.Minute)
+				return presented
+			},
+			wantErr: utils.ErrInvalidRefreshToken,
+		},
+		{
+			name: "unknown token is refused",
+			setup: func(t *testing.T, u *userUseCase, repo *fakeRefreshTokenRepository) string {
+				return "unknown"
+			},
+			wantErr: utils.ErrInvalidRefreshToken,
+		},
+		{
+			name: "token of a blocked user is refused",
+			setup: func(t *testing.T, u *userUseCase, repo *fakeRefreshTokenRepository) string {
+				return startSession(t, u, "user-blocked")
+			},
+			wantErr: utils.ErrUserBlocked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRefreshTokenRepository()
+			u := newTestUserUseCase(repo,
+				&entity.User{UUID: "user-1", Email: "user@example.com"},
+				&entity.User{UUID: "user-blocked", Email: "blocked@example.com", IsBlocked: true},
+			)
+
+			presented := tt.setup(t, u, repo)
+
+			output, err := u.RefreshToken(context.Background(), presented)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RefreshToken() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil {
+				next := repo.token(output.RefreshToken)
+				if next == nil || next.FamilyID != repo.token(presented).FamilyID {
+					t.Error("new refresh token is not stored in the family of the presented one")
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, repo, presented)
+			}
+		})
+	}
+}
+
+// newTestUserUseCase creates a user use case over in-memory repositories
+func newTestUserUseCase(refreshTokens *fakeRefreshTokenRepository, users ...*entity.User) *userUseCase {
+	return NewUserUseCase(
+		newFakeUserRepository(users...),
+		refreshTokens,
+		nil,
+		nil,
+		nil,
+		&fakeTokenService{},
+		nil,
+		time.Hour,
+		0,
+		nil,
+	).(*userUseCase)
+}
+
+// startSession starts a session for the user and returns its refresh token
+func startSession(t *testing.T, u *userUseCase, userUUID string) string {
+	t.Helper()
+
+	user, err := u.userRepository.GetUserByUUID(context.Background(), userUUID)
+	if err != nil {
+		t.Fatalf("get user: %v", err)
+	}
+
+	output, err := u.sessions.start(context.Background(), user)
+	if err != nil {
+		t.Fatalf("start session: %v", err)
+	}
+	return output.RefreshToken
+}
@@ -0,0 +1,39 @@
+package validator
+
+import (
+	"regexp"
+
+	"github.com/edutav/licentia-usoris/internal/utils"
+)
+
+var (
+	roleNameRegex       = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,49}$`)
+	permissionNameRegex = regexp.MustCompile(`^[a-z][a-z_-]*:[a-z][a-z0-9_-]*$`)
+	groupNameRegex      = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,49}$`)
+)
+
+func ValidateRoleName(name string) error {
+	if !roleNameRegex.MatchString(name) {
+		return utils.ErrInvalidRoleName
+	}
+
+	return nil
+}
+
+// ValidatePermissionName checks that the permission follows the "verb:resource" format
+func ValidatePermissionName(name string) error {
+	if len(name) > 100 || !permissionNameRegex.MatchString(name) {
+		return utils.ErrInvalidPermissionName
+	}
+
+	return nil
+}
+
+// ValidateGroupName checks that the group name is a lowercase slug
+func ValidateGroupName(name string) error {
+	if !groupNameRegex.MatchString(name) {
+		return utils.ErrInvalidGroupName
+	}
+
+	return nil
+}
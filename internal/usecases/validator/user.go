@@ -12,11 +12,11 @@ type ValidatePasswordFunc func(password string) error
 var ValidateUserPassword ValidatePasswordFunc = defaultValidateUserPassword
 
 func defaultValidateUserPassword(password string) error {
-	if len(password) < 8 {
+	if len(password) < utils.PasswordMinLength {
 		return utils.ErrPasswordTooShort
 	}
 
-	if len(password) > 64 {
+	if len(password) > utils.PasswordMaxLength {
 		return utils.ErrPasswordTooLong
 	}
 
@@ -48,11 +48,11 @@ func defaultValidateUserPassword(password string) error {
 }
 
 func ValidateUserName(name string) error {
-	if len(name) < 2 {
+	if len(name) < utils.UserNameMinLength {
 		return utils.ErrUserNameTooShort
 	}
 
-	if len(name) > 200 {
+	if len(name) > utils.UserNameMaxLength {
 		return utils.ErrUserNameTooLong
 	}
 
@@ -0,0 +1,157 @@
+package usecases
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/edutav/licentia-usoris/internal/domain/entity"
+	"github.com/edutav/licentia-usoris/internal/domain/reporitory"
+	"github.com/edutav/licentia-usoris/internal/utils"
+)
+
+// Purposes of the verification codes, so a code sent for one flow cannot be used in another
+const (
+	VerificationPurposePreRegistration = "pre_registration"
+)
+
+// Window the daily limit of sends is counted in
+const verificationSendWindow = 24 * time.Hour
+
+type VerificationCodeUseCase interface {
+	// Generate a code for the purpose and subject, replacing the previous one.
+	// Fails when the previous one was issued within the cooldown or the daily limit was reached.
+	Issue(ctx context.Context, purpose, subject string) (string, error)
+
+	// Check and consume the code of the purpose and subject
+	Verify(ctx context.Context, purpose, subject, code string) error
+}
+
+type verificationCodeUseCase struct {
+	verificationCodeRepository reporitory.VerificationCodeRepository
+	secret                     []byte
+	codeTTL                    time.Duration
+	maxAttempts                int
+	resendCooldown             time.Duration
+	dailyLimit                 int
+}
+
+// NewVerificationCodeUseCase creates a new verification code use case.
+// The codes are stored as HMAC-SHA256 hashes keyed with the secret.
+func NewVerificationCodeUseCase(
+	verificationCodeRepository reporitory.VerificationCodeRepository,
+	secret string,
+	codeTTL time.Duration,
+	maxAttempts int,
+	resendCooldown time.Duration,
+	dailyLimit int,
+) VerificationCodeUseCase {
+	if codeTTL <= 0 {
+		codeTTL = 15 * time.Minute
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = 5
+	}
+	if resendCooldown <= 0 {
+		resendCooldown = time.Minute
+	}
+	if dailyLimit <= 0 {
+		dailyLimit = 5
+	}
+	return &verificationCodeUseCase{
+		verificationCodeRepository: verificationCodeRepository,
+		secret:                     []byte(secret),
+		codeTTL:                    codeTTL,
+		maxAttempts:                maxAttempts,
+		resendCooldown:             resendCooldown,
+		dailyLimit:                 dailyLimit,
+	}
+}
+
+// Issue implements VerificationCodeUseCase.
+func (u *verificationCodeUseCase) Issue(ctx context.Context, purpose, subject string) (string, error) {
+	now := time.Now().UTC()
+
+	previous, err := u.verificationCodeRepository.GetVerificationCode(ctx, purpose, subject)
+	if err != nil && err != utils.ErrInvalidOTP {
+		return "", err
+	}
+
+	// A new day window starts on the first send after the previous one ended
+	sendCount, sendWindowStartedAt := 1, now
+	if previous != nil {
+		if now.Before(previous.CreatedAt.Add(u.resendCooldown)) {
+			return "", utils.ErrOTPCooldown
+		}
+
+		if now.Before(previous.SendWindowStartedAt.Add(verificationSendWindow)) {
+			if previous.SendCount >= u.dailyLimit {
+				return "", utils.ErrOTPDailyLimit
+			}
+			sendCount, sendWindowStartedAt = previous.SendCount+1, previous.SendWindowStartedAt
+		}
+	}
+
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", utils.ErrGenerateOTP
+	}
+	code := fmt.Sprintf("%06d", n.Int64())
+
+	err = u.verificationCodeRepository.SaveVerificationCode(ctx, &entity.VerificationCode{
+		Purpose:             purpose,
+		Subject:             subject,
+		CodeHash:            u.hash(purpose, subject, code),
+		ExpiresAt:           now.Add(u.codeTTL),
+		CreatedAt:           now,
+		SendCount:           sendCount,
+		SendWindowStartedAt: sendWindowStartedAt,
+	}, now.Add(-u.resendCooldown))
+	if err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+// Verify implements VerificationCodeUseCase.
+//
+// Every attempt counts, the right one included, and the code is locked once
+// maxAttempts were made. A new code has to be issued then.
+func (u *verificationCodeUseCase) Verify(ctx context.Context, purpose, subject, code string) error {
+	current, err := u.verificationCodeRepository.GetVerificationCode(ctx, purpose, subject)
+	if err != nil {
+		return err
+	}
+
+	if current.IsConsumed() {
+		return utils.ErrInvalidOTP
+	}
+
+	if current.IsExpired() {
+		return utils.ErrOTPExpired
+	}
+
+	err = u.verificationCodeRepository.AddVerificationCodeAttempt(ctx, current.UUID, u.maxAttempts)
+	if err != nil {
+		return err
+	}
+
+	if !hmac.Equal([]byte(u.hash(purpose, subject, code)), []byte(current.CodeHash)) {
+		return utils.ErrInvalidOTP
+	}
+
+	return u.verificationCodeRepository.ConsumeVerificationCode(ctx, current.UUID)
+}
+
+// hash binds the code to its purpose and subject
+func (u *verificationCodeUseCase) hash(purpose, subject, code string) string {
+	mac := hmac.New(sha256.New, u.secret)
+	mac.Write([]byte(purpose + "\x00" + subject + "\x00" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,96 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/edutav/licentia-usoris/internal/utils"
+)
+
+func TestVerifyLockout(t *testing.T) {
+	const maxAttempts = 3
+
+	tests := []struct {
+		name string
+		// attempts are made in order, "right" standing for the issued code
+		attempts []string
+		// setup changes the stored code before the attempts
+		setup   func(repo *fakeVerificationCodeRepository)
+		wantErr []error
+	}{
+		{
+			name:     "right code",
+			attempts: []string{"right"},
+			wantErr:  []error{nil},
+		},
+		{
+			name:     "right code after wrong ones",
+			attempts: []string{"000000", "000000", "right"},
+			wantErr:  []error{utils.ErrInvalidOTP, utils.ErrInvalidOTP, nil},
+		},
+		{
+			name:     "locked after the last attempt",
+			attempts: []string{"000000", "000000", "000000", "right"},
+			wantErr:  []error{utils.ErrInvalidOTP, utils.ErrInvalidOTP, utils.ErrInvalidOTP, utils.ErrOTPLocked},
+		},
+		{
+			name:     "code used once",
+			attempts: []string{"right", "right"},
+			wantErr:  []error{nil, utils.ErrInvalidOTP},
+		},
+		{
+			name:     "expired code",
+			attempts: []string{"right"},
+			setup: func(repo *fakeVerificationCodeRepository) {
+				for _, code := range repo.codes {
+					code.ExpiresAt = time.Now().UTC().Add(-time.Minute)
+				}
+			},
+			wantErr: []error{utils.ErrOTPExpired},
+		},
+		{
+			name:     "code of another purpose",
+			attempts: []string{"right"},
+			setup: func(repo *fakeVerificationCodeRepository) {
+				for key, code := range repo.codes {
+					delete(repo.codes, key)
+					code.Purpose = "password_reset"
+					repo.codes[code.Purpose+"/"+code.Subject] = code
+				}
+			},
+			wantErr: []error{utils.ErrInvalidOTP},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeVerificationCodeRepository()
+			u := NewVerificationCodeUseCase(repo, "test-secret", 0, maxAttempts, 0, 0)
+
+			code, err := u.Issue(context.Background(), VerificationPurposePreRegistration, "user@example.com")
+			if err != nil {
+				t.Fatalf("Issue() error = %v", err)
+			}
+
+			if tt.setup != nil {
+				tt.setup(repo)
+			}
+
+			for i, attempt := range tt.attempts {
+				if attempt == "right" {
+					attempt = code
+				} else if attempt == code {
+					// The random code happened to be the wrong guess
+					attempt = "999999"
+				}
+
+				err := u.Verify(context.Background(), VerificationPurposePreRegistration, "user@example.com", attempt)
+				if !errors.Is(err, tt.wantErr[i]) {
+					t.Fatalf("attempt %d: Verify() error = %v, want %v", i+1, err, tt.wantErr[i])
+				}
+			}
+		})
+	}
+}
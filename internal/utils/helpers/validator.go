@@ -52,3 +52,12 @@ func ValidatePhoneNumber(phoneNumber string) error {
 
 	return nil
 }
+
+func ValidateUUID(uuid string) error {
+	uuidRegex := regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	if !uuidRegex.MatchString(uuid) {
+		return utils.ErrInvalidUUID
+	}
+
+	return nil
+}
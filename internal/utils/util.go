@@ -2,11 +2,27 @@ package utils
 
 import "errors"
 
+// Limits of the user fields, checked by the validators and quoted by the error messages
 const (
-	InternalServerErrorString = "Internal server error"
+	PasswordMinLength    = 8
+	PasswordMaxLength    = 64
+	UserNameMinLength    = 2
+	UserNameMaxLength    = 200
+	BlockReasonMaxLength = 500
 )
 
 var (
+	// request errors
+	ErrInvalidContentType   = errors.New("invalid content type")
+	ErrInvalidRequestBody   = errors.New("invalid request body")
+	ErrInvalidBoolean       = errors.New("invalid boolean value")
+	ErrRouteNotFound        = errors.New("route not found")
+	ErrMethodNotAllowed     = errors.New("method not allowed")
+	ErrMissingBearerToken   = errors.New("no bearer token given")
+	ErrTokenRevoked         = errors.New("token has been revoked")
+	ErrAuthenticationNeeded = errors.New("authentication required")
+	ErrMissingRefreshToken  = errors.New("no refresh token given")
+
 	// email errors
 	ErrInvalidEmail    = errors.New("invalid email format")
 	ErrMissingEmail    = errors.New("no email input given")
@@ -27,13 +43,71 @@ var (
 	ErrPasswordInvalid         = errors.New("invalid password")
 	ErrInvalidPhoneNumber      = errors.New("invalid phone number")
 	ErrMissingPhoneNumber      = errors.New("no phone number input given")
+	ErrInvalidSortField        = errors.New("invalid sort field")
+	ErrInvalidSortOrder        = errors.New("invalid sort order")
+	ErrPageOutOfRange          = errors.New("page out of range")
+	ErrUserNotBlocked          = errors.New("user is not blocked")
+	ErrUserNotDeleted          = errors.New("user is not deleted")
+	ErrUserAlreadyBlocked      = errors.New("user already blocked")
+	ErrUserAlreadyDeleted      = errors.New("user already deleted")
+	ErrMissingBlockReason      = errors.New("no block reason given")
+	ErrBlockReasonTooLong      = errors.New("block reason too long")
+	ErrUserExceedsCaller       = errors.New("user holds permissions the caller does not hold")
 
 	// Pre-registration errors
 	ErrCreateVericationEntry    = errors.New("error creating verification entry")
 	ErrPreRegistredUserNotFound = errors.New("pre-registred user not found")
 
 	// login errors
+	ErrInvalidCredentials       = errors.New("invalid email or password")
+	ErrUserBlocked              = errors.New("user is blocked")
+	ErrUserDeleted              = errors.New("user is deleted")
+	ErrEmailNotVerified         = errors.New("email not verified")
 	ErrGenerateJWTTokenWithRole = errors.New("error generate jwt token with role")
+	ErrMissingJWTSecret         = errors.New("jwt secret not configured")
+	ErrInvalidToken             = errors.New("invalid token")
+	ErrTokenExpired             = errors.New("token has expired")
+	ErrGenerateRefreshToken     = errors.New("error generating refresh token")
+	ErrInvalidRefreshToken      = errors.New("invalid refresh token")
+	ErrRefreshTokenReused       = errors.New("refresh token reused")
+
+	// password errors
+	ErrGeneratePasswordResetToken = errors.New("error generating password reset token")
+	ErrInvalidPasswordResetToken  = errors.New("invalid or expired password reset token")
+	ErrIncorrectPassword          = errors.New("current password is incorrect")
+	ErrPasswordReused             = errors.New("password was used recently")
+
+	// authorization errors
+	ErrPermissionDenied = errors.New("permission denied")
+
+	// role and permission errors
+	ErrRoleNotFound          = errors.New("role not found")
+	ErrDuplicateRole         = errors.New("role already exists")
+	ErrInvalidRoleName       = errors.New("invalid role name")
+	ErrRoleNotAssigned       = errors.New("role not assigned to user")
+	ErrPermissionNotFound    = errors.New("permission not found")
+	ErrDuplicatePermission   = errors.New("permission already exists")
+	ErrInvalidPermissionName = errors.New("invalid permission name")
+	ErrPermissionNotAttached = errors.New("permission not attached to role")
+	ErrRoleExceedsGrantor    = errors.New("grants permissions the caller does not hold")
+	ErrInvalidUUID           = errors.New("invalid uuid")
+
+	// group errors
+	ErrGroupNotFound        = errors.New("group not found")
+	ErrDuplicateGroup       = errors.New("group already exists")
+	ErrInvalidGroupName     = errors.New("invalid group name")
+	ErrGroupMemberNotFound  = errors.New("user is not a member of the group")
+	ErrGroupRoleNotAssigned = errors.New("role not assigned to group")
+
+	// mfa errors
+	ErrInvalidEncryptionKey  = errors.New("encryption key must be 32 bytes encoded in base64")
+	ErrDecryptSecret         = errors.New("error decrypting secret")
+	ErrMFANotEnrolled        = errors.New("two-factor authentication not enrolled")
+	ErrMFAAlreadyEnabled     = errors.New("two-factor authentication already enabled")
+	ErrMFANotEnabled         = errors.New("two-factor authentication not enabled")
+	ErrInvalidMFACode        = errors.New("invalid two-factor authentication code")
+	ErrInvalidMFAChallenge   = errors.New("invalid or expired two-factor authentication challenge")
+	ErrGenerateRecoveryCodes = errors.New("error generating recovery codes")
 
 	// otp errors
 	ErrGenerateOTP        = errors.New("error generating otp")
@@ -43,4 +117,7 @@ var (
 	ErrOTPExpired         = errors.New("OTP has expired")
 	ErrOTPAlreadyVerified = errors.New("email already verified")
 	ErrInvalidOTP         = errors.New("invalid OTP")
+	ErrOTPLocked          = errors.New("too many invalid OTP attempts")
+	ErrOTPCooldown        = errors.New("OTP requested too recently")
+	ErrOTPDailyLimit      = errors.New("too many OTP requests today")
 )